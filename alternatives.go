@@ -0,0 +1,95 @@
+// Finding several good, but different, rosters for commissioners to choose
+// from.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Number of solutions to choose alternatives from
+const hallOfFameSize = 1000
+
+// hallOfFame remembers the best different solutions seen during a run
+type hallOfFame struct {
+	size      int
+	solutions []Solution // sorted by score
+	seen      map[string]bool
+}
+
+func newHallOfFame(size int) *hallOfFame {
+	return &hallOfFame{size: size, seen: make(map[string]bool)}
+}
+
+// add remembers the solution if it's one of the best seen, and isn't the same
+// as one we already have but for its team labels
+func (hall *hallOfFame) add(solution Solution) {
+	if len(hall.solutions) == hall.size &&
+		solution.score >= hall.solutions[len(hall.solutions)-1].score {
+		return
+	}
+	description := canonicalTeams(solution.players)
+	if hall.seen[description] {
+		return
+	}
+	position := sort.Search(len(hall.solutions), func(i int) bool {
+		return hall.solutions[i].score > solution.score
+	})
+	hall.solutions = append(hall.solutions, Solution{})
+	copy(hall.solutions[position+1:], hall.solutions[position:])
+	hall.solutions[position] = solution
+	hall.seen[description] = true
+	if len(hall.solutions) > hall.size {
+		worst := hall.solutions[len(hall.solutions)-1]
+		delete(hall.seen, canonicalTeams(worst.players))
+		hall.solutions = hall.solutions[:hall.size]
+	}
+}
+
+// ChooseAlternatives greedily picks up to numAlternatives of the best
+// candidates, skipping any that are fewer than minDistance players away from
+// one already picked.
+//
+// Returns the alternatives, best first.
+func ChooseAlternatives(candidates []Solution, numAlternatives int,
+	minDistance int) []Solution {
+	candidates = append([]Solution{}, candidates...)
+	sort.Stable(ByScore(candidates))
+	alternatives := []Solution{}
+	for _, candidate := range candidates {
+		if len(alternatives) == numAlternatives {
+			break
+		}
+		different := true
+		for _, alternative := range alternatives {
+			if solutionDistance(alternative.players, candidate.players) < minDistance {
+				different = false
+				break
+			}
+		}
+		if different {
+			alternatives = append(alternatives, candidate)
+		}
+	}
+	return alternatives
+}
+
+// PrintAlternatives shows the score and teams of each alternative after the
+// first (the best), lined up with the best's teams, along with who is placed
+// differently
+func PrintAlternatives(alternatives []Solution) {
+	best := alternatives[0]
+	for i, alternative := range alternatives[1:] {
+		aligned := Solution{alignTeams(best.players, alternative.players), alternative.score}
+		fmt.Printf("\nAlternative %d of %d. Score: %.02f (%.02f worse than the best)\n",
+			i+2, len(alternatives), aligned.score, aligned.score-best.score)
+		for j, player := range aligned.players {
+			if player.team != best.players[j].team {
+				fmt.Printf("%v moves from team %d to team %d\n",
+					player.name, best.players[j].team+1, player.team+1)
+			}
+		}
+		PrintTeams(aligned)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHallOfFame(t *testing.T) {
+	hall := newHallOfFame(2)
+	players := makeRandomPlayers(30)
+	hall.add(Solution{players, 3})
+	hall.add(Solution{relabeledCopy(players), 1}) // a duplicate
+	hall.add(Solution{makeRandomPlayers(30), 2})
+	hall.add(Solution{makeRandomPlayers(30), 4}) // not good enough
+	hall.add(Solution{makeRandomPlayers(30), 0})
+	assert.Equal(t, 2, len(hall.solutions))
+	assert.Equal(t, Score(0), hall.solutions[0].score)
+	assert.Equal(t, Score(2), hall.solutions[1].score)
+}
+
+func TestChooseAlternatives(t *testing.T) {
+	players := makeRandomPlayers(30)
+	nearby := relabeledCopy(players)
+	nearby[0].team = uint8((int(nearby[0].team) + 1) % numTeams)
+	farAway := makeRandomPlayers(30)
+	candidates := []Solution{{farAway, 3}, {nearby, 2}, {players, 1}}
+
+	alternatives := ChooseAlternatives(candidates, 3, 2)
+	assert.Equal(t, 2, len(alternatives))
+	assert.Equal(t, Score(1), alternatives[0].score)
+	assert.Equal(t, Score(3), alternatives[1].score)
+
+	alternatives = ChooseAlternatives(candidates, 3, 1)
+	assert.Equal(t, 3, len(alternatives))
+}
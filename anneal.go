@@ -0,0 +1,89 @@
+// Simulated annealing, an alternative search strategy to the genetic algorithm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// annealSchedule describes how we cool down while annealing. The temperature
+// falls geometrically from startTemperature to endTemperature over numSteps.
+type annealSchedule struct {
+	startTemperature, endTemperature float64
+	numSteps                         int
+}
+
+// temperature returns the temperature at the given step
+func (schedule annealSchedule) temperature(step int) float64 {
+	progress := float64(step) / float64(schedule.numSteps)
+	return schedule.startTemperature *
+		math.Pow(schedule.endTemperature/schedule.startTemperature, progress)
+}
+
+// Anneal searches for a better solution by repeatedly mutating the current one
+// with the mutation operators. Improvements are always kept. Changes that make
+// the score worse are kept with a chance that shrinks as the temperature drops.
+//
+// Returns the best solution found. The input solution is not modified.
+func Anneal(index *rosterIndex, start Solution, schedule annealSchedule,
+	doneSignal <-chan os.Signal) Solution {
+	players := make([]Player, len(start.players))
+	copy(players, start.players)
+	engine := newScoringEngine(index, players)
+	score := engine.score()
+
+	best := make([]Player, len(players))
+	copy(best, players)
+	bestScore := score
+	bestStep := 0
+	previousTeams := make([]uint8, len(players))
+	step := 0
+annealing:
+	for ; step < schedule.numSteps; step++ {
+		// Check for the exit signal every so often
+		if step%10000 == 0 {
+			select {
+			case <-doneSignal:
+				fmt.Println("Exit signal received")
+				break annealing
+			default:
+			}
+		}
+
+		for i := range players {
+			previousTeams[i] = players[i].team
+		}
+		newScore := score
+		if operator := applyMutationOperator(engine); operator != nil {
+			newScore = engine.score()
+			operator.record(score, newScore)
+		}
+		temperature := schedule.temperature(step)
+		if newScore <= score ||
+			rand.Float64() < math.Exp(float64(score-newScore)/temperature) {
+			score = newScore
+		} else {
+			// Reject the change by moving everybody back
+			for i, team := range previousTeams {
+				engine.move(i, team)
+			}
+			continue
+		}
+
+		if score < bestScore {
+			copy(best, players)
+			bestScore = score
+			bestStep = step
+			newLog.Debug("New top score! Step %d at temperature %.03f. Score: %.02f",
+				step, temperature, bestScore)
+		}
+	}
+
+	fmt.Printf("Annealed for %d steps. Top score was found on step #%d\n",
+		step, bestStep)
+	fmt.Println(MutationStats())
+	return Solution{best, bestScore}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnealSchedule(t *testing.T) {
+	schedule := annealSchedule{10, 0.1, 100}
+	assert.InDelta(t, 10, schedule.temperature(0), 1e-9)
+	assert.InDelta(t, 1, schedule.temperature(50), 1e-9)
+	assert.InDelta(t, 0.1, schedule.temperature(100), 1e-9)
+}
+
+func TestAnneal(t *testing.T) {
+	players := makeRandomPlayers(30)
+	startScore, _ := ScoreSolution(players)
+	start := Solution{players, startScore}
+	startPlayers := make([]Player, len(players))
+	copy(startPlayers, players)
+
+	annealed := Anneal(newRosterIndex(players), start, annealSchedule{10, 0.01, 5000},
+		make(chan os.Signal))
+	assert.True(t, annealed.score <= start.score)
+	annealedScore, _ := ScoreSolution(annealed.players)
+	assert.InDelta(t, float64(annealedScore), float64(annealed.score), 1e-3)
+	assert.Equal(t, startPlayers, start.players)
+}
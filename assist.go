@@ -0,0 +1,217 @@
+// An interactive assistant for live captains' drafts. The commissioner enters
+// each pick as it happens. After each pick we re-optimize the rest of the
+// rosters with the drafted players pinned to their teams, and suggest players
+// for the team on the clock.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// assistSettings are how much work to do after each pick
+type assistSettings struct {
+	// number of random starting points to polish when re-optimizing
+	numRestarts int
+	// number of players to suggest for the team on the clock
+	numSuggestions int
+}
+
+// assistant follows the draft as the picks are entered
+type assistant struct {
+	players  []Player
+	pinned   []bool // whether each player has been drafted
+	picks    []int  // the drafted players, in the order they were picked
+	order    draftSettings
+	settings assistSettings
+}
+
+// suggestion is a player the team on the clock could pick, and the score of
+// the best rosters we found with them on the team
+type suggestion struct {
+	player int
+	score  Score
+}
+
+func newAssistant(players []Player, order draftSettings,
+	settings assistSettings) *assistant {
+	a := assistant{
+		players:  make([]Player, len(players)),
+		pinned:   make([]bool, len(players)),
+		order:    order,
+		settings: settings,
+	}
+	copy(a.players, players)
+	return &a
+}
+
+// onTheClock returns the team making the next pick
+func (a *assistant) onTheClock() uint8 {
+	round := len(a.picks) / numTeams
+	return uint8(a.order.pickingOrder(round)[len(a.picks)%numTeams])
+}
+
+// findUndrafted returns the index of the first undrafted player with the name,
+// ignoring case.
+//
+// Returns error if there's no such player, or they've all been drafted.
+func (a *assistant) findUndrafted(name string) (int, error) {
+	drafted := -1
+	for i, player := range a.players {
+		if !strings.EqualFold(player.name.String(), name) {
+			continue
+		}
+		if !a.pinned[i] {
+			return i, nil
+		}
+		drafted = i
+	}
+	if drafted >= 0 {
+		return -1, fmt.Errorf("%v has already been drafted", a.players[drafted].name)
+	}
+	return -1, fmt.Errorf("no player named '%s'", name)
+}
+
+// pick puts the named player on the team on the clock.
+//
+// Returns error if they can't be picked.
+func (a *assistant) pick(name string) error {
+	i, err := a.findUndrafted(name)
+	if err != nil {
+		return err
+	}
+	a.players[i].team = a.onTheClock()
+	a.pinned[i] = true
+	a.picks = append(a.picks, i)
+	return nil
+}
+
+// undo takes back the last pick.
+//
+// Returns error if there haven't been any.
+func (a *assistant) undo() error {
+	if len(a.picks) == 0 {
+		return fmt.Errorf("no picks to undo")
+	}
+	last := a.picks[len(a.picks)-1]
+	a.pinned[last] = false
+	a.picks = a.picks[:len(a.picks)-1]
+	return nil
+}
+
+// optimize finds the best rosters it can with the drafted players on their
+// teams, by polishing a few random placements of the undrafted players
+func (a *assistant) optimize() Solution {
+	var best Solution
+	for restart := 0; restart < a.settings.numRestarts || restart == 0; restart++ {
+		players := make([]Player, len(a.players))
+		copy(players, a.players)
+		for i := range players {
+			if !a.pinned[i] {
+				players[i].team = uint8(rand.Intn(numTeams))
+			}
+		}
+		solution := polishPinned(Solution{players, 0}, a.pinned)
+		if restart == 0 || solution.score < best.score {
+			best = solution
+		}
+	}
+	best.score, _ = ScoreSolution(best.players)
+	return best
+}
+
+// suggest ranks the undrafted players by the score of the rosters we'd make if
+// the team on the clock picked them, starting from the optimized rosters
+func (a *assistant) suggest(optimized Solution) []suggestion {
+	team := a.onTheClock()
+	pinned := make([]bool, len(a.pinned))
+	copy(pinned, a.pinned)
+	suggestions := []suggestion{}
+	for i := range a.players {
+		if a.pinned[i] {
+			continue
+		}
+		players := make([]Player, len(optimized.players))
+		copy(players, optimized.players)
+		players[i].team = team
+		pinned[i] = true
+		solution := polishPinned(Solution{players, 0}, pinned)
+		pinned[i] = false
+		score, _ := ScoreSolution(solution.players)
+		suggestions = append(suggestions, suggestion{i, score})
+	}
+	sort.SliceStable(suggestions, func(x, y int) bool {
+		return suggestions[x].score < suggestions[y].score
+	})
+	if len(suggestions) > a.settings.numSuggestions {
+		suggestions = suggestions[:a.settings.numSuggestions]
+	}
+	return suggestions
+}
+
+// advise re-optimizes and prints the suggestions for the team on the clock
+func (a *assistant) advise() {
+	optimized := a.optimize()
+	fmt.Printf("With the players drafted so far, the best rosters we found "+
+		"score %.02f\n", optimized.score)
+	fmt.Printf("Pick %d: team %d is on the clock. Best picks:\n",
+		len(a.picks)+1, a.onTheClock()+1)
+	for rank, s := range a.suggest(optimized) {
+		fmt.Printf("%3d. %-30v final score %.02f (%+.02f)\n", rank+1,
+			a.players[s.player], s.score, s.score-optimized.score)
+	}
+}
+
+// RunAssistant reads the picks from input until the draft is done or the input
+// ends. Each line is the name of the player picked by the team on the clock,
+// or "undo" to take back the last pick, "teams" to show the teams so far, or
+// "quit". Returns the players, with the drafted ones on their teams.
+func RunAssistant(players []Player, order draftSettings, settings assistSettings,
+	input io.Reader) []Player {
+	a := newAssistant(players, order, settings)
+	scanner := bufio.NewScanner(input)
+	a.advise()
+	for len(a.picks) < len(a.players) {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			fmt.Println()
+			return a.players
+		}
+		var err error
+		switch line := strings.TrimSpace(scanner.Text()); strings.ToLower(line) {
+		case "":
+			continue
+		case "quit":
+			return a.players
+		case "teams":
+			drafted := []Player{}
+			for _, i := range a.picks {
+				drafted = append(drafted, a.players[i])
+			}
+			PrintTeams(Solution{drafted, 0})
+			continue
+		case "undo":
+			err = a.undo()
+		default:
+			err = a.pick(line)
+		}
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if len(a.picks) < len(a.players) {
+			a.advise()
+		}
+	}
+	fmt.Println("The draft is done")
+	drafted := Solution{a.players, 0}
+	drafted.score, _ = ScoreSolution(drafted.players)
+	PrintTeams(drafted)
+	PrintSolutionScoring(drafted)
+	return a.players
+}
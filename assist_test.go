@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolishPinned(t *testing.T) {
+	players := makeRandomPlayers(18)
+	pinned := make([]bool, len(players))
+	pinned[0], pinned[5] = true, true
+	polished := polishPinned(Solution{players, 0}, pinned)
+	assert.Equal(t, players[0].team, polished.players[0].team)
+	assert.Equal(t, players[5].team, polished.players[5].team)
+	score, _ := ScoreSolution(polished.players)
+	assert.InDelta(t, float64(score), float64(polished.score), 1e-3)
+}
+
+func TestAssistantPicks(t *testing.T) {
+	players := makeDraftPlayers(2 * numTeams)
+	a := newAssistant(players, draftSettings{"snake", "best"}, assistSettings{1, 3})
+	assert.NotNil(t, a.undo())
+	assert.NotNil(t, a.pick("nobody"))
+	for i := 0; i < numTeams; i++ {
+		assert.Nil(t, a.pick(players[i].name.String()))
+	}
+	assert.NotNil(t, a.pick("a player"))
+	// Snake order comes back the other way
+	assert.Equal(t, uint8(numTeams-1), a.onTheClock())
+	assert.Equal(t, uint8(numTeams-1), a.players[numTeams-1].team)
+
+	optimized := a.optimize()
+	for i := 0; i < numTeams; i++ {
+		assert.Equal(t, uint8(i), optimized.players[i].team)
+	}
+	suggestions := a.suggest(optimized)
+	assert.Equal(t, 3, len(suggestions))
+	for i, s := range suggestions {
+		assert.False(t, a.pinned[s.player])
+		if i > 0 {
+			assert.True(t, suggestions[i-1].score <= s.score)
+		}
+	}
+
+	assert.Nil(t, a.undo())
+	assert.False(t, a.pinned[numTeams-1])
+	assert.Nil(t, a.pick(strings.ToUpper(players[numTeams-1].name.String())))
+}
+
+func TestAssistantDuplicateNames(t *testing.T) {
+	players := makeDraftPlayers(numTeams)
+	players[3].name = players[0].name
+	a := newAssistant(players, draftSettings{"linear", "best"}, assistSettings{1, 1})
+	assert.Nil(t, a.pick(players[0].name.String()))
+	assert.Nil(t, a.pick(players[0].name.String()))
+	assert.True(t, a.pinned[3])
+	assert.NotNil(t, a.pick(players[0].name.String()))
+}
+
+// captureStdout returns what f prints
+func captureStdout(f func()) string {
+	reader, writer, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = writer
+	output := make(chan string)
+	go func() {
+		printed, _ := io.ReadAll(reader)
+		output <- string(printed)
+	}()
+	f()
+	writer.Close()
+	os.Stdout = stdout
+	return <-output
+}
+
+func TestRunAssistant(t *testing.T) {
+	players := makeDraftPlayers(numTeams + 1)
+	names := []string{}
+	for _, player := range players {
+		names = append(names, player.name.String())
+	}
+	// Runs until every player is picked, despite the bad lines
+	input := "teams\nundo\nnobody\n\n" + strings.Join(names, "\n") + "\nextra\n"
+	var drafted []Player
+	output := captureStdout(func() {
+		drafted = RunAssistant(players, draftSettings{"linear", "best"},
+			assistSettings{1, 2}, strings.NewReader(input))
+	})
+	assert.Contains(t, output, "no picks to undo")
+	assert.Contains(t, output, "no player named 'nobody'")
+	assert.Contains(t, output, "The draft is done")
+	assert.NotContains(t, output, "extra")
+	// The teams pick in order, and the first team picks again
+	for i, player := range drafted {
+		assert.Equal(t, players[i].name, player.name)
+		assert.Equal(t, uint8(i%numTeams), player.team)
+	}
+}
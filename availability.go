@@ -0,0 +1,245 @@
+// Availability across the season's schedule. A team balanced on paper can be
+// short-handed on game day if its players can't make it, so we balance each
+// team's expected attendance and expected rating on every date.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Weights of the criteria added with --availability. They match the weights of
+// the number of players and the average rating.
+const (
+	attendanceWeight     = 8
+	expectedRatingWeight = 8
+)
+
+// availability holds the chance of each player attending each date
+type availability struct {
+	dates   []string
+	chances map[Name][]float64
+}
+
+// parseChance reads whether a player can attend: yes (y, x or 1), no (n or 0)
+// or a chance between 0 and 1. Blank means we don't know, so they're counted as
+// attending.
+//
+// Returns error if it's none of those.
+func parseChance(s string) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "yes", "y", "x":
+		return 1, nil
+	case "no", "n":
+		return 0, nil
+	}
+	chance, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || chance < 0 || chance > 1 {
+		return 0, fmt.Errorf("invalid availability '%s'", s)
+	}
+	return chance, nil
+}
+
+// chance of the player attending the date. Players who aren't in the
+// availability file are counted as attending every date.
+func (a availability) chance(player Player, date int) float64 {
+	if chances, found := a.chances[player.name]; found {
+		return chances[date]
+	}
+	return 1
+}
+
+// forecast returns the team's expected attendance and expected rating on each
+// date. The expected rating is the average rating of the players, weighted by
+// their chance of attending, or 0 if nobody is expected.
+func (a availability) forecast(team Team) (attendance []float64, ratings []float64) {
+	attendance = make([]float64, len(a.dates))
+	ratings = make([]float64, len(a.dates))
+	for date := range a.dates {
+		for _, player := range team.players {
+			chance := a.chance(player, date)
+			attendance[date] += chance
+			ratings[date] += chance * float64(player.rating)
+		}
+		if attendance[date] > 0 {
+			ratings[date] /= attendance[date]
+		}
+	}
+	return attendance, ratings
+}
+
+// availabilityCriterion balances either the teams' expected attendance, or
+// their expected rating, on every date
+type availabilityCriterion struct {
+	name         string
+	availability availability
+	rating       bool // whether to balance the expected rating
+}
+
+// availabilityFactory makes a factory for availabilityCriterions, which read
+// the availability file named in their argument
+func availabilityFactory(rating bool) CriterionFactory {
+	return func(name string, argument string) (Criterion, error) {
+		if argument == "" {
+			return nil, fmt.Errorf("criterion '%s' needs an availability file", name)
+		}
+		availability, err := ParseAvailability(argument)
+		if err != nil {
+			return nil, err
+		}
+		return availabilityCriterion{name, availability, rating}, nil
+	}
+}
+
+func (c availabilityCriterion) Name() string {
+	return c.name
+}
+
+// dateValues returns the value of each team on each date
+func (c availabilityCriterion) dateValues(teams []Team) [][]float64 {
+	values := make([][]float64, len(c.availability.dates))
+	for date := range values {
+		values[date] = make([]float64, len(teams))
+	}
+	for i, team := range teams {
+		attendance, ratings := c.availability.forecast(team)
+		for date := range values {
+			if c.rating {
+				values[date][i] = ratings[date]
+			} else {
+				values[date][i] = attendance[date]
+			}
+		}
+	}
+	return values
+}
+
+// Score is the standard deviation of the teams' values on each date, averaged
+// over the dates
+func (c availabilityCriterion) Score(teams []Team) Score {
+	score, _ := c.scoreAndValues(teams)
+	return score
+}
+
+// RawValues is each team's value, averaged over the dates
+func (c availabilityCriterion) RawValues(teams []Team) []float64 {
+	_, averages := c.scoreAndValues(teams)
+	return averages
+}
+
+// scoreAndValues finds the score and the raw values from one forecast
+func (c availabilityCriterion) scoreAndValues(teams []Team) (Score, []float64) {
+	averages := make([]float64, len(teams))
+	dateValues := c.dateValues(teams)
+	if len(dateValues) == 0 {
+		return 0, averages
+	}
+	score := 0.0
+	for _, values := range dateValues {
+		score += sampleStandardDeviation(values)
+		for i, value := range values {
+			averages[i] += value / float64(len(dateValues))
+		}
+	}
+	return Score(score / float64(len(dateValues))), averages
+}
+
+// boundFunction is the score when everybody is on one team
+func (c availabilityCriterion) boundFunction() criterionBoundFunction {
+	return func(players []Player) Score {
+		teams := make([]Team, numTeams)
+		teams[0].players = players
+		return c.Score(teams)
+	}
+}
+
+// AvailabilityCriteria makes the criteria for the availability file: one
+// balancing expected attendance, and one balancing expected rating. They share
+// the file, so it's only read once.
+//
+// Returns error if the file is invalid.
+func AvailabilityCriteria(availabilityFilename string) ([]criterion, error) {
+	availability, err := ParseAvailability(availabilityFilename)
+	if err != nil {
+		return nil, err
+	}
+	return []criterion{
+		scorerCriterion(availabilityCriterion{"expected attendance", availability, false},
+			nil, 0, attendanceWeight),
+		scorerCriterion(availabilityCriterion{"expected rating", availability, true},
+			nil, 0, expectedRatingWeight),
+	}, nil
+}
+
+// ValidateAvailability checks the availability file against the players, if
+// any criterion looks at availability, and warns about anybody in it who isn't
+// a player, since they'd be ignored.
+//
+// Returns error if one of its dates is a column of the players file, like
+// "Gender", so isn't really a date.
+func ValidateAvailability(players []Player) error {
+	// The criteria from --availability share one file, so warn about it once
+	warned := make(map[Name]bool)
+	for _, criterion := range criteriaToScore {
+		c, ok := criterion.scorer.(availabilityCriterion)
+		if !ok || len(players) == 0 {
+			continue
+		}
+		for _, date := range c.availability.dates {
+			if _, found := players[0].attributes[date]; found {
+				return fmt.Errorf("criterion '%s' has a date '%s', which is a column "+
+					"of the players file", criterion.name, date)
+			}
+		}
+		isPlayer := make(map[Name]bool)
+		for _, player := range players {
+			isPlayer[player.name] = true
+		}
+		unknown := []string{}
+		for name := range c.availability.chances {
+			if !isPlayer[name] && !warned[name] {
+				unknown = append(unknown, name.String())
+				warned[name] = true
+			}
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			newLog.Warning("%s is in the availability file, but isn't a player", name)
+		}
+	}
+	return nil
+}
+
+// PrintAttendanceForecast shows each team's expected attendance and expected
+// rating on every date, if any criterion looks at availability
+func PrintAttendanceForecast(teams []Team) {
+	for _, criterion := range criteriaToScore {
+		c, ok := criterion.scorer.(availabilityCriterion)
+		if !ok {
+			continue
+		}
+		fmt.Println("Expected attendance (and rating) by date:")
+		writer := new(tabwriter.Writer)
+		writer.Init(os.Stdout, 0, 0, 1, ' ', 0)
+		forecasts := make([][2][]float64, len(teams))
+		for i, team := range teams {
+			attendance, ratings := c.availability.forecast(team)
+			forecasts[i] = [2][]float64{attendance, ratings}
+		}
+		for date, name := range c.availability.dates {
+			fmt.Fprintf(writer, "%s\t", name)
+			for _, forecast := range forecasts {
+				fmt.Fprintf(writer, "|%.01f (%.02f)\t", forecast[0][date], forecast[1][date])
+			}
+			fmt.Fprintln(writer, "|")
+		}
+		writer.Flush()
+		// The criteria from --availability share one file, so show it once
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChance(t *testing.T) {
+	for s, expected := range map[string]float64{
+		"": 1, "Yes": 1, "x": 1, "1": 1, "n": 0, "0": 0, " 0.25 ": 0.25} {
+		chance, err := parseChance(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, chance, s)
+	}
+	for _, s := range []string{"maybe", "2", "-0.5"} {
+		_, err := parseChance(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestAvailabilityCriteria(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "availability.csv")
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"First Name,Last Name,May 1,May 8\nA,A,yes,no\nB,B,0.5,yes\n"), 0644))
+	criteria, err := AvailabilityCriteria(filename)
+	assert.Nil(t, err)
+	attendance := criteria[0].scorer.(availabilityCriterion)
+	assert.Equal(t, []string{"May 1", "May 8"}, attendance.availability.dates)
+
+	// C isn't in the file, so is expected every date
+	team := Team{[]Player{{name: Name{"A", "A"}, rating: 80},
+		{name: Name{"B", "B"}, rating: 40}, {name: Name{"C", "C"}, rating: 40}}}
+	expectedAttendance, expectedRatings := attendance.availability.forecast(team)
+	assert.Equal(t, []float64{2.5, 2}, expectedAttendance)
+	assert.InDelta(t, 56, expectedRatings[0], 1e-9)
+	assert.InDelta(t, 40, expectedRatings[1], 1e-9)
+
+	teams := make([]Team, numTeams)
+	for i := range teams {
+		teams[i].players = []Player{{name: Name{"C", "C"}, rating: 50}}
+	}
+	assert.Equal(t, Score(0), criteria[0].rawScore(teams))
+	assert.Equal(t, Score(0), criteria[1].rawScore(teams))
+	teams[0].players = team.players
+	assert.InDelta(t, (0.6124+0.4082)/2, float64(criteria[0].rawScore(teams)), 1e-3)
+	assert.Equal(t, []float64{2.25, 1, 1, 1, 1, 1}, attendance.RawValues(teams))
+
+	// Every criterion shares the file
+	assert.Equal(t, attendance.availability.dates,
+		criteria[1].scorer.(availabilityCriterion).availability.dates)
+
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"First Name,Last Name,May 1\nA,A,maybe\n"), 0644))
+	_, err = AvailabilityCriteria(filename)
+	assert.NotNil(t, err)
+	assert.Nil(t, os.WriteFile(filename, []byte("Name,May 1\nA,yes\n"), 0644))
+	_, err = AvailabilityCriteria(filename)
+	assert.NotNil(t, err)
+}
+
+func TestValidateAvailability(t *testing.T) {
+	defer saveWorstCases()()
+	filename := filepath.Join(t.TempDir(), "availability.csv")
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"First Name,Last Name,Gender,May 1\nA,A,1,yes\nZ,Z,0,no\n"), 0644))
+	criteria, err := AvailabilityCriteria(filename)
+	assert.Nil(t, err)
+	assert.Nil(t, SetCriteria(append(criteriaToScore, criteria...)))
+	players := []Player{{name: Name{"A", "A"}, rating: 50,
+		attributes: map[string]string{"First Name": "A", "Last Name": "A"}}}
+	// Z isn't a player, which is only a warning
+	assert.Nil(t, ValidateAvailability(players))
+	players[0].attributes["Gender"] = "Male"
+	assert.NotNil(t, ValidateAvailability(players))
+}
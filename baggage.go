@@ -0,0 +1,300 @@
+// Checks and policies to run on the baggages after they've been parsed
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/topher200/baseutil"
+)
+
+type BaggagePolicy uint8
+
+const (
+	// Every baggage request counts, even if only one side asked for it
+	HonorAllBaggages BaggagePolicy = iota
+	// Only count baggages that both players asked for
+	HonorMutualBaggages
+	// Treat every one-way request as if both players asked for it
+	SymmetrizeBaggages
+)
+
+// StringToBaggagePolicy parses a raw input string into a BaggagePolicy.
+//
+// The string must be one of "all", "mutual" or "symmetrize", or we return
+// error.
+func StringToBaggagePolicy(s string) (BaggagePolicy, error) {
+	switch s {
+	case "all":
+		return HonorAllBaggages, nil
+	case "mutual":
+		return HonorMutualBaggages, nil
+	case "symmetrize":
+		return SymmetrizeBaggages, nil
+	}
+	return HonorAllBaggages, fmt.Errorf("invalid baggage policy '%s'", s)
+}
+
+// Baggage is a single request by one player to be on the same team as another
+type Baggage struct {
+	player, baggage Name
+}
+
+func hasBaggage(player Player, name Name) bool {
+	for _, baggage := range player.baggages {
+		if baggage == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FindOneWayBaggages returns every baggage request that the requested player
+// didn't make in return.
+//
+// Requests for players that aren't in the list of players are included.
+func FindOneWayBaggages(players []Player) (oneWayBaggages []Baggage) {
+	for _, player := range players {
+		for _, baggage := range player.baggages {
+			baggagePlayer, err := FindPlayer(players, baggage)
+			if err != nil || !hasBaggage(*baggagePlayer, player.name) {
+				oneWayBaggages = append(oneWayBaggages, Baggage{player.name, baggage})
+			}
+		}
+	}
+	return
+}
+
+// ValidateBaggages logs a warning for each one-way baggage, so that they can be
+// followed up on before the rosters are generated.
+func ValidateBaggages(players []Player) {
+	for _, baggage := range FindOneWayBaggages(players) {
+		if _, err := FindPlayer(players, baggage.baggage); err != nil {
+			newLog.Warning("%v requested %v as baggage, but %v is not a known player",
+				baggage.player, baggage.baggage, baggage.baggage)
+			continue
+		}
+		newLog.Warning("%v requested %v as baggage, but it was not requested in return",
+			baggage.player, baggage.baggage)
+	}
+}
+
+// ApplyBaggagePolicy has the side effect of rewriting the .baggages for all
+// Players to match the given policy.
+func ApplyBaggagePolicy(players []Player, policy BaggagePolicy) {
+	oneWayBaggages := FindOneWayBaggages(players)
+	for _, oneWay := range oneWayBaggages {
+		player, err := FindPlayer(players, oneWay.player)
+		baseutil.Check(err)
+		switch policy {
+		case HonorMutualBaggages:
+			player.baggages = removeBaggage(player.baggages, oneWay.baggage)
+			newLog.Debug("Dropped one-way baggage of %v for %v",
+				oneWay.baggage, oneWay.player)
+		case SymmetrizeBaggages:
+			baggagePlayer, err := FindPlayer(players, oneWay.baggage)
+			if err != nil {
+				// We can't give baggage to someone who isn't playing
+				continue
+			}
+			baggagePlayer.baggages = append(baggagePlayer.baggages, oneWay.player)
+			newLog.Debug("Added returning baggage of %v for %v",
+				oneWay.player, oneWay.baggage)
+		}
+	}
+}
+
+// removeBaggage returns a copy of baggages without the given name
+func removeBaggage(baggages []Name, name Name) []Name {
+	remaining := []Name{}
+	for _, baggage := range baggages {
+		if baggage != name {
+			remaining = append(remaining, baggage)
+		}
+	}
+	return remaining
+}
+
+// EnforceMaxBaggages has the side effect of dropping every baggage past the
+// first maxBaggages requested by each player. Ignored if maxBaggages is 0.
+func EnforceMaxBaggages(players []Player, maxBaggages int) {
+	if maxBaggages <= 0 {
+		return
+	}
+	for i := range players {
+		if len(players[i].baggages) <= maxBaggages {
+			continue
+		}
+		for _, baggage := range players[i].baggages[maxBaggages:] {
+			newLog.Warning("%v requested more than %d baggages; dropped %v",
+				players[i].name, maxBaggages, baggage)
+		}
+		players[i].baggages = players[i].baggages[:maxBaggages]
+	}
+}
+
+// baggageLink is an undirected baggage between the players at two indices
+type baggageLink struct {
+	a, b int
+}
+
+// findBaggageLinks returns each pair of players connected by a baggage (in
+// either direction) exactly once.
+func findBaggageLinks(players []Player) (links []baggageLink) {
+	seen := make(map[baggageLink]bool)
+	for i, player := range players {
+		for _, baggage := range player.baggages {
+			for j := range players {
+				if players[j].name != baggage || i == j {
+					continue
+				}
+				link := baggageLink{i, j}
+				if j < i {
+					link = baggageLink{j, i}
+				}
+				if !seen[link] {
+					seen[link] = true
+					links = append(links, link)
+				}
+			}
+		}
+	}
+	return
+}
+
+// connectedGroups returns the indices of the players in each group of at least
+// two players connected by the given links.
+func connectedGroups(numPlayers int, links []baggageLink) (groups [][]int) {
+	neighbors := make([][]int, numPlayers)
+	for _, link := range links {
+		neighbors[link.a] = append(neighbors[link.a], link.b)
+		neighbors[link.b] = append(neighbors[link.b], link.a)
+	}
+	visited := make([]bool, numPlayers)
+	for start := 0; start < numPlayers; start++ {
+		if visited[start] || len(neighbors[start]) == 0 {
+			continue
+		}
+		group := []int{start}
+		visited[start] = true
+		for i := 0; i < len(group); i++ {
+			for _, neighbor := range neighbors[group[i]] {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					group = append(group, neighbor)
+				}
+			}
+		}
+		groups = append(groups, group)
+	}
+	return
+}
+
+// largestGroup returns the size of the largest group in groups
+func largestGroup(groups [][]int) int {
+	largest := 0
+	for _, group := range groups {
+		if len(group) > largest {
+			largest = len(group)
+		}
+	}
+	return largest
+}
+
+// BaggageGroups returns the players in each chain of baggages. Players with no
+// baggages (in either direction) aren't in any group.
+func BaggageGroups(players []Player) [][]Player {
+	indexGroups := connectedGroups(len(players), findBaggageLinks(players))
+	groups := make([][]Player, len(indexGroups))
+	for i, indexGroup := range indexGroups {
+		for _, index := range indexGroup {
+			groups[i] = append(groups[i], players[index])
+		}
+	}
+	return groups
+}
+
+// LinksToBreakGroups returns a set of baggages which, if dropped, would leave
+// no baggage group larger than maxGroupSize.
+//
+// We greedily drop the link that best splits up the largest group, then add
+// back any dropped link that turned out to be unnecessary.
+func LinksToBreakGroups(players []Player, maxGroupSize int) []Baggage {
+	links := findBaggageLinks(players)
+	fits := func(links []baggageLink) bool {
+		return largestGroup(connectedGroups(len(players), links)) <= maxGroupSize
+	}
+	without := func(links []baggageLink, i int) []baggageLink {
+		remaining := make([]baggageLink, 0, len(links)-1)
+		remaining = append(remaining, links[:i]...)
+		return append(remaining, links[i+1:]...)
+	}
+
+	dropped := []baggageLink{}
+	for !fits(links) {
+		degree := make([]int, len(players))
+		for _, link := range links {
+			degree[link.a] += 1
+			degree[link.b] += 1
+		}
+		// Choose the link that leaves the smallest largest group. Break ties
+		// (such as links inside a loop, which split nothing) by dropping the link
+		// between the most connected players.
+		bestIndex, bestSize, bestDegree := 0, len(players)+1, -1
+		for i, link := range links {
+			size := largestGroup(connectedGroups(len(players), without(links, i)))
+			linkDegree := degree[link.a] + degree[link.b]
+			if size < bestSize || (size == bestSize && linkDegree > bestDegree) {
+				bestIndex, bestSize, bestDegree = i, size, linkDegree
+			}
+		}
+		dropped = append(dropped, links[bestIndex])
+		links = without(links, bestIndex)
+	}
+
+	// Put back the links that we didn't need to drop after all
+	linksToDrop := []Baggage{}
+	for i := len(dropped) - 1; i >= 0; i-- {
+		if fits(append(links, dropped[i])) {
+			links = append(links, dropped[i])
+			continue
+		}
+		linksToDrop = append(linksToDrop,
+			Baggage{players[dropped[i].a].name, players[dropped[i].b].name})
+	}
+	return linksToDrop
+}
+
+// AnalyzeBaggageGroups logs a warning for each baggage group too large to fit
+// on a team, along with the baggages to drop to break the groups up.
+//
+// A group is too large if it has more players than maxGroupSize or than a team
+// has room for. maxGroupSize is ignored if 0.
+func AnalyzeBaggageGroups(players []Player, maxGroupSize int) {
+	teamCapacity := (len(players) + numTeams - 1) / numTeams
+	if maxGroupSize <= 0 || maxGroupSize > teamCapacity {
+		maxGroupSize = teamCapacity
+	}
+	groups := BaggageGroups(players)
+	tooLarge := false
+	for _, group := range groups {
+		names := make([]Name, len(group))
+		for i, player := range group {
+			names[i] = player.name
+		}
+		newLog.Debug("Found baggage group of %d players: %v", len(group), names)
+		if len(group) > maxGroupSize {
+			tooLarge = true
+			newLog.Warning(
+				"Baggage group of %d players is larger than the limit of %d: %v",
+				len(group), maxGroupSize, names)
+		}
+	}
+	if !tooLarge {
+		return
+	}
+	for _, baggage := range LinksToBreakGroups(players, maxGroupSize) {
+		newLog.Warning("Suggest dropping the baggage between %v and %v",
+			baggage.player, baggage.baggage)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeBaggagePlayers() []Player {
+	players := make([]Player, 3)
+	players[0] = Player{name: Name{"A", "Player"}, rating: 100, gender: Male,
+		baggages: []Name{Name{"B", "Player"}}}
+	players[1] = Player{name: Name{"B", "Player"}, rating: 100, gender: Male,
+		baggages: []Name{Name{"A", "Player"}}}
+	players[2] = Player{name: Name{"C", "Player"}, rating: 100, gender: Female,
+		baggages: []Name{Name{"A", "Player"}}}
+	return players
+}
+
+func TestStringToBaggagePolicy(t *testing.T) {
+	policy, err := StringToBaggagePolicy("all")
+	assert.Nil(t, err)
+	assert.Equal(t, HonorAllBaggages, policy)
+	policy, err = StringToBaggagePolicy("mutual")
+	assert.Nil(t, err)
+	assert.Equal(t, HonorMutualBaggages, policy)
+	policy, err = StringToBaggagePolicy("symmetrize")
+	assert.Nil(t, err)
+	assert.Equal(t, SymmetrizeBaggages, policy)
+	_, err = StringToBaggagePolicy("")
+	assert.NotNil(t, err)
+}
+
+func TestFindOneWayBaggages(t *testing.T) {
+	players := makeBaggagePlayers()
+	oneWay := FindOneWayBaggages(players)
+	assert.Equal(t, []Baggage{Baggage{Name{"C", "Player"}, Name{"A", "Player"}}}, oneWay)
+
+	// Requests for unknown players are always one-way
+	players[1].baggages = append(players[1].baggages, Name{"D", "Player"})
+	assert.Equal(t, 2, len(FindOneWayBaggages(players)))
+}
+
+func TestApplyBaggagePolicy(t *testing.T) {
+	players := makeBaggagePlayers()
+	ApplyBaggagePolicy(players, HonorAllBaggages)
+	assert.Equal(t, 1, len(players[0].baggages))
+	assert.Equal(t, 1, len(players[2].baggages))
+
+	players = makeBaggagePlayers()
+	ApplyBaggagePolicy(players, HonorMutualBaggages)
+	assert.Equal(t, 1, len(players[0].baggages))
+	assert.Equal(t, 0, len(players[2].baggages))
+
+	players = makeBaggagePlayers()
+	ApplyBaggagePolicy(players, SymmetrizeBaggages)
+	assert.Equal(t, []Name{Name{"B", "Player"}, Name{"C", "Player"}}, players[0].baggages)
+	assert.Equal(t, 1, len(players[2].baggages))
+	assert.Equal(t, 0, len(FindOneWayBaggages(players)))
+}
+
+func makeChainPlayers(numPlayers int) []Player {
+	players := make([]Player, numPlayers)
+	for i := range players {
+		players[i] = Player{name: Name{string(rune('A' + i)), "Player"}, rating: 100,
+			gender: Male, baggages: []Name{}}
+		if i > 0 {
+			players[i].baggages = []Name{players[i-1].name}
+		}
+	}
+	return players
+}
+
+func TestEnforceMaxBaggages(t *testing.T) {
+	players := makeBaggagePlayers()
+	players[0].baggages = append(players[0].baggages, Name{"C", "Player"})
+	EnforceMaxBaggages(players, 0)
+	assert.Equal(t, 2, len(players[0].baggages))
+	EnforceMaxBaggages(players, 1)
+	assert.Equal(t, []Name{Name{"B", "Player"}}, players[0].baggages)
+	assert.Equal(t, 1, len(players[2].baggages))
+}
+
+func TestBaggageGroups(t *testing.T) {
+	players := makeBaggagePlayers()
+	groups := BaggageGroups(players)
+	assert.Equal(t, 1, len(groups))
+	assert.Equal(t, 3, len(groups[0]))
+
+	players = makeChainPlayers(5)
+	players[2].baggages = []Name{}
+	groups = BaggageGroups(players)
+	assert.Equal(t, 2, len(groups))
+	assert.Equal(t, 2, len(groups[0]))
+	assert.Equal(t, 3, len(groups[1]))
+}
+
+func TestLinksToBreakGroups(t *testing.T) {
+	players := makeChainPlayers(5)
+	assert.Equal(t, 0, len(LinksToBreakGroups(players, 5)))
+	// A chain of five is broken by dropping the middle
+	assert.Equal(t, 1, len(LinksToBreakGroups(players, 3)))
+	assert.Equal(t, 2, len(LinksToBreakGroups(players, 2)))
+
+	// Closing the chain into a loop means one more link has to go
+	players[0].baggages = []Name{players[4].name}
+	linksToDrop := LinksToBreakGroups(players, 3)
+	assert.Equal(t, 2, len(linksToDrop))
+	for _, baggage := range linksToDrop {
+		player, err := FindPlayer(players, baggage.player)
+		assert.Nil(t, err)
+		player.baggages = removeBaggage(player.baggages, baggage.baggage)
+		other, err := FindPlayer(players, baggage.baggage)
+		assert.Nil(t, err)
+		other.baggages = removeBaggage(other.baggages, baggage.player)
+	}
+	for _, group := range BaggageGroups(players) {
+		assert.True(t, len(group) <= 3)
+	}
+}
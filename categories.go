@@ -0,0 +1,135 @@
+// Spreading players evenly by a categorical column of the players file, like
+// their club, neighborhood or age band. This generalizes balancing the number
+// of males and females on each team to any column.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// Weight of each criterion added with --spread-by. It matches the weight of
+// spreading the males and females.
+const categoryWeight = 1200
+
+// categoryCriterion scores how unevenly each value of a column is spread
+// across the teams. Players with a blank value aren't counted.
+type categoryCriterion struct {
+	name   string
+	column string
+}
+
+// newCategoryCriterion spreads players by the column named in the argument.
+//
+// Returns error if no column is given.
+func newCategoryCriterion(name string, argument string) (Criterion, error) {
+	if argument == "" {
+		return nil, fmt.Errorf("criterion '%s' needs a column to spread by", name)
+	}
+	return categoryCriterion{name, argument}, nil
+}
+
+// categoryCounts returns the sorted values of the column, and how many players
+// on each team have each value
+func (c categoryCriterion) categoryCounts(teams []Team) ([]string, map[string][]int) {
+	counts := make(map[string][]int)
+	values := []string{}
+	for i, team := range teams {
+		for _, player := range team.players {
+			value := player.attributes[c.column]
+			if value == "" {
+				continue
+			}
+			if _, found := counts[value]; !found {
+				counts[value] = make([]int, len(teams))
+				values = append(values, value)
+			}
+			counts[value][i] += 1
+		}
+	}
+	sort.Strings(values)
+	return values, counts
+}
+
+func (c categoryCriterion) Name() string {
+	return c.name
+}
+
+// Score is like playerCountDifference for each value of the column: how much
+// more than 1 apart the largest and smallest counts are, summed over the
+// values.
+func (c categoryCriterion) Score(teams []Team) Score {
+	_, counts := c.categoryCounts(teams)
+	score := 0
+	for _, teamCounts := range counts {
+		min, max := teamCounts[0], teamCounts[0]
+		for _, count := range teamCounts {
+			if count < min {
+				min = count
+			}
+			if count > max {
+				max = count
+			}
+		}
+		if max-min > 1 {
+			score += max - min - 1
+		}
+	}
+	return Score(score)
+}
+
+func (c categoryCriterion) RawValues(teams []Team) []float64 {
+	return []float64{}
+}
+
+// boundFunction is the score when everybody is on one team
+func (c categoryCriterion) boundFunction() criterionBoundFunction {
+	return func(players []Player) Score {
+		teams := make([]Team, numTeams)
+		teams[0].players = players
+		return c.Score(teams)
+	}
+}
+
+// ValidateCategories checks that every column spread by is in the players file
+//
+// Returns error naming the first missing column.
+func ValidateCategories(players []Player) error {
+	for _, criterion := range criteriaToScore {
+		category, ok := criterion.scorer.(categoryCriterion)
+		if !ok || len(players) == 0 {
+			continue
+		}
+		if _, found := players[0].attributes[category.column]; !found {
+			return fmt.Errorf("criterion '%s' spreads by column '%s', which isn't "+
+				"in the players file", criterion.name, category.column)
+		}
+	}
+	return nil
+}
+
+// PrintCategoryHistograms shows how many players of each value are on each
+// team, for every criterion that spreads by a column
+func PrintCategoryHistograms(teams []Team) {
+	for _, criterion := range criteriaToScore {
+		category, ok := criterion.scorer.(categoryCriterion)
+		if !ok {
+			continue
+		}
+		values, counts := category.categoryCounts(criterion.filterTeams(teams))
+		fmt.Printf("%s, by team:\n", criterion.name)
+		writer := new(tabwriter.Writer)
+		writer.Init(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+		for _, value := range values {
+			fmt.Fprintf(writer, "%s\t", value)
+			for _, count := range counts[value] {
+				fmt.Fprintf(writer, "%d\t", count)
+			}
+			fmt.Fprintln(writer)
+		}
+		writer.Flush()
+	}
+}
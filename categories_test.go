@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeClubTeams puts the players of each club on the given teams
+func makeClubTeams(clubs map[string][]int) []Team {
+	teams := make([]Team, numTeams)
+	for club, clubTeams := range clubs {
+		for _, team := range clubTeams {
+			teams[team].players = append(teams[team].players,
+				Player{attributes: map[string]string{"Club": club}})
+		}
+	}
+	return teams
+}
+
+func TestCategoryCriterion(t *testing.T) {
+	scorer, err := newCategoryCriterion("spread of Club", "Club")
+	assert.Nil(t, err)
+	_, err = newCategoryCriterion("spread of nothing", "")
+	assert.NotNil(t, err)
+
+	// One of each on every team is perfectly spread
+	teams := makeClubTeams(map[string][]int{
+		"Red": {0, 1, 2, 3, 4, 5}, "Blue": {0, 1, 2, 3, 4, 5}})
+	assert.Equal(t, Score(0), scorer.Score(teams))
+
+	// Counts one apart can't be helped
+	teams = makeClubTeams(map[string][]int{"Red": {0, 0, 1, 2, 3, 4, 5}})
+	assert.Equal(t, Score(0), scorer.Score(teams))
+
+	// Three Reds on team 0 and none on team 5 are two further apart than the one
+	// allowed. Blanks don't count.
+	teams = makeClubTeams(map[string][]int{
+		"Red": {0, 0, 0, 1, 2, 3, 4}, "Blue": {0, 1, 2, 3, 4, 5}, "": {5, 5, 5}})
+	assert.Equal(t, Score(2), scorer.Score(teams))
+	values, counts := scorer.(categoryCriterion).categoryCounts(teams)
+	assert.Equal(t, []string{"Blue", "Red"}, values)
+	assert.Equal(t, []int{3, 1, 1, 1, 1, 0}, counts["Red"])
+
+	// Everybody on one team is as bad as it gets
+	players := append(teams[0].players, teams[1].players...)
+	assert.Equal(t, Score(4), scorer.(categoryCriterion).boundFunction()(players))
+}
+
+func TestValidateCategories(t *testing.T) {
+	defer saveWorstCases()()
+	criterion, err := newCriterion("spread of Club", "category", "Club", nil, 0, 1)
+	assert.Nil(t, err)
+	assert.Nil(t, SetCriteria(append(criteriaToScore, criterion)))
+	players := []Player{{attributes: map[string]string{"Club": ""}}}
+	assert.Nil(t, ValidateCategories(players))
+	players[0].attributes = map[string]string{"Age": "30"}
+	assert.NotNil(t, ValidateCategories(players))
+}
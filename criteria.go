@@ -0,0 +1,192 @@
+// Criteria are pluggable. Each kind of criterion is registered by name with a
+// factory, so the criteria to score with can be listed in a config file, and
+// new kinds can be added without changing the scoring code.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Criterion scores how balanced the teams are in one dimension. Lower scores
+// are more balanced.
+type Criterion interface {
+	Name() string
+	// Score returns the raw score of the teams
+	Score(teams []Team) Score
+	// RawValues returns the value for each team that the score was calculated
+	// from. If a value for each team doesn't make much sense, it's empty.
+	RawValues(teams []Team) []float64
+}
+
+// A CriterionFactory makes a Criterion with the given name. The argument comes
+// from the config file, and its meaning is up to the kind of criterion.
+type CriterionFactory func(name string, argument string) (Criterion, error)
+
+// aggregateCriterion is implemented by criteria that a scoringEngine can score
+// from its running totals for each team, which is much faster than Score.
+// Returns nil if the criterion can't be.
+type aggregateCriterion interface {
+	aggregateFunction() criterionAggregateFunction
+}
+
+// boundedCriterion is implemented by criteria that know the largest raw score
+// they could have, for analytic normalization. Returns nil if they don't.
+type boundedCriterion interface {
+	boundFunction() criterionBoundFunction
+}
+
+// valuesCriterion is implemented by criteria that find their score from their
+// raw values, so both can be had from one pass over the teams
+type valuesCriterion interface {
+	scoreAndValues(teams []Team) (Score, []float64)
+}
+
+// criterionRegistry holds the factory for each kind of criterion
+var criterionRegistry = map[string]CriterionFactory{
+	"baggages": functionFactory(
+		baggagesMatch, baggagesMatchAggregate, baggagesMatchBound),
+	"count": functionFactory(
+		playerCountDifference, playerCountDifferenceAggregate, playerCountDifferenceBound),
+	"average": functionFactory(
+		ratingDifference, ratingDifferenceAggregate, ratingDifferenceBound),
+	"stddev":          functionFactory(ratingStdDev, ratingStdDevAggregate, ratingStdDevBound),
+	"median":          functionFactory(ratingMedianDifference, nil, ratingDifferenceBound),
+	"spread":          functionFactory(ratingSpread, ratingSpreadAggregate, ratingSpreadBound),
+	"script":          newScriptCriterion,
+	"category":        newCategoryCriterion,
+	"teammates":       newTeammatesCriterion,
+	"role":            newRoleCriterion,
+	"positions":       newPositionCriterion,
+	"attendance":      availabilityFactory(false),
+	"expected rating": availabilityFactory(true),
+}
+
+// RegisterCriterion adds a new kind of criterion.
+//
+// Returns error if the kind is already registered.
+func RegisterCriterion(kind string, factory CriterionFactory) error {
+	if _, found := criterionRegistry[kind]; found {
+		return fmt.Errorf("a criterion named '%s' is already registered", kind)
+	}
+	criterionRegistry[kind] = factory
+	return nil
+}
+
+// CriterionKinds returns the names of the registered kinds of criteria
+func CriterionKinds() []string {
+	kinds := []string{}
+	for kind := range criterionRegistry {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// functionCriterion is a Criterion calculated by a
+// criterionCalculationFunction, optionally with functions to aggregate and
+// bound it
+type functionCriterion struct {
+	name      string
+	calculate criterionCalculationFunction
+	aggregate criterionAggregateFunction
+	bound     criterionBoundFunction
+}
+
+func (c functionCriterion) Name() string {
+	return c.name
+}
+
+func (c functionCriterion) Score(teams []Team) Score {
+	score, _ := c.calculate(teams)
+	return score
+}
+
+func (c functionCriterion) RawValues(teams []Team) []float64 {
+	_, rawValues := c.calculate(teams)
+	return rawValues
+}
+
+func (c functionCriterion) scoreAndValues(teams []Team) (Score, []float64) {
+	return c.calculate(teams)
+}
+
+func (c functionCriterion) aggregateFunction() criterionAggregateFunction {
+	return c.aggregate
+}
+
+func (c functionCriterion) boundFunction() criterionBoundFunction {
+	return c.bound
+}
+
+// functionFactory makes a factory for functionCriterions. They don't take an
+// argument.
+func functionFactory(calculate criterionCalculationFunction,
+	aggregate criterionAggregateFunction, bound criterionBoundFunction) CriterionFactory {
+	return func(name string, argument string) (Criterion, error) {
+		if argument != "" {
+			return nil, fmt.Errorf("criterion '%s' doesn't take an argument", name)
+		}
+		return functionCriterion{name, calculate, aggregate, bound}, nil
+	}
+}
+
+// newCriterion makes a criterion of the given kind to score with.
+//
+// Returns error if the kind isn't registered, or its factory fails.
+func newCriterion(name string, kind string, argument string, filter PlayerFilter,
+	numPlayers int, weight int) (criterion, error) {
+	factory, found := criterionRegistry[kind]
+	if !found {
+		return criterion{}, fmt.Errorf("no kind of criterion named '%s'", kind)
+	}
+	scorer, err := factory(name, argument)
+	if err != nil {
+		return criterion{}, err
+	}
+	return scorerCriterion(scorer, filter, numPlayers, weight), nil
+}
+
+// scorerCriterion makes a criterion to score with from a Criterion that's
+// already been made
+func scorerCriterion(scorer Criterion, filter PlayerFilter, numPlayers int,
+	weight int) criterion {
+	c := criterion{name: scorer.Name(), scorer: scorer, filter: filter,
+		numPlayers: numPlayers, weight: weight}
+	if aggregate, ok := scorer.(aggregateCriterion); ok {
+		c.aggregate = aggregate.aggregateFunction()
+	}
+	if bound, ok := scorer.(boundedCriterion); ok {
+		c.bound = bound.boundFunction()
+	}
+	return c
+}
+
+// mustNewCriterion is newCriterion for the built-in criteria, which can't fail
+func mustNewCriterion(name string, kind string, filter PlayerFilter, numPlayers int,
+	weight int) criterion {
+	c, err := newCriterion(name, kind, "", filter, numPlayers, weight)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// SetCriteria replaces the criteria we score with.
+//
+// Returns error if there are none, or two have the same name.
+func SetCriteria(criteria []criterion) error {
+	if len(criteria) == 0 {
+		return fmt.Errorf("there must be at least one criterion")
+	}
+	names := make(map[string]bool)
+	for _, c := range criteria {
+		if names[c.name] {
+			return fmt.Errorf("two criteria are named '%s'", c.name)
+		}
+		names[c.name] = true
+	}
+	criteriaToScore = criteria
+	return nil
+}
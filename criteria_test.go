@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// teamSizeCriterion is a custom criterion: the difference between the largest
+// and smallest teams
+type teamSizeCriterion struct{}
+
+func (teamSizeCriterion) Name() string {
+	return "team size"
+}
+
+func (c teamSizeCriterion) Score(teams []Team) Score {
+	low, high := valueRange(c.RawValues(teams))
+	return Score(high - low)
+}
+
+func (teamSizeCriterion) RawValues(teams []Team) []float64 {
+	sizes := make([]float64, len(teams))
+	for i, team := range teams {
+		sizes[i] = float64(len(team.players))
+	}
+	return sizes
+}
+
+func TestCustomCriterion(t *testing.T) {
+	defer saveWorstCases()()
+	assert.Nil(t, RegisterCriterion("size", func(name string, argument string) (Criterion, error) {
+		return teamSizeCriterion{}, nil
+	}))
+	defer delete(criterionRegistry, "size")
+	assert.NotNil(t, RegisterCriterion("size", nil))
+
+	custom, err := newCriterion("sizes", "size", "", IsMale, 0, 3)
+	assert.Nil(t, err)
+	assert.Nil(t, custom.aggregate)
+	_, err = newCriterion("sizes", "no such kind", "", nil, 0, 3)
+	assert.NotNil(t, err)
+
+	// The engine falls back to the criterion's Score
+	assert.Nil(t, SetCriteria(append(criteriaToScore, custom)))
+	players := makeRandomPlayers(30)
+	score, _ := ScoreSolution(players)
+	engine := newScoringEngine(newRosterIndex(players), players)
+	assert.InDelta(t, float64(score), float64(engine.score()), 1e-3)
+
+	assert.NotNil(t, SetCriteria(append(criteriaToScore, custom)))
+	assert.NotNil(t, SetCriteria([]criterion{}))
+}
+
+func TestBuiltInCriteria(t *testing.T) {
+	for _, c := range criteriaToScore {
+		assert.NotNil(t, c.aggregate, c.name)
+		assert.NotNil(t, c.bound, c.name)
+	}
+
+	teams := make([]Team, numTeams)
+	for _, rating := range []float32{10, 20, 60} {
+		teams[0].players = append(teams[0].players, Player{rating: rating})
+		teams[1].players = append(teams[1].players, Player{rating: rating + 1})
+	}
+	_, medians := ratingMedianDifference(teams)
+	assert.Equal(t, []float64{20, 21, 0, 0, 0, 0}, medians)
+	spread, averages := ratingSpread(teams)
+	assert.InDelta(t, 31.0, float64(spread), 1e-6)
+	assert.InDelta(t, 30.0, averages[0], 1e-6)
+}
+
+func TestParseCriteria(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "criteria.csv")
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"Name,Criterion,Filter,Top Players,Weight,Argument\n"+
+			"baggages,baggages,,,1000,\n"+
+			"top male medians,median,Male,3,5,\n"), 0644))
+	criteria, err := ParseCriteria(filename)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(criteria))
+	assert.Equal(t, "top male medians", criteria[1].name)
+	assert.Equal(t, 3, criteria[1].numPlayers)
+	assert.Equal(t, 5, criteria[1].weight)
+	assert.Nil(t, criteria[1].aggregate)
+
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"Name,Criterion,Filter,Top Players,Weight,Argument\n"+
+			"baggages,baggages,,,1000,an argument\n"), 0644))
+	_, err = ParseCriteria(filename)
+	assert.NotNil(t, err)
+}
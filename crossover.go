@@ -0,0 +1,129 @@
+// Team-aware crossover. Team numbers are arbitrary, so two solutions can hold
+// the same team under different numbers. We line the teams up before combining.
+
+package main
+
+import "math/rand"
+
+// teamOverlap counts the players on each pair of teams. overlap[a][b] is the
+// number of players on team a in players1 and team b in players2.
+func teamOverlap(players1 []Player, players2 []Player) [][]int {
+	overlap := make([][]int, numTeams)
+	for a := range overlap {
+		overlap[a] = make([]int, numTeams)
+	}
+	for i := range players1 {
+		overlap[players1[i].team][players2[i].team] += 1
+	}
+	return overlap
+}
+
+// maximumOverlapAssignment pairs up each row with a column to get the largest
+// total overlap, using the Hungarian algorithm.
+//
+// Returns the column assigned to each row.
+func maximumOverlapAssignment(overlap [][]int) []int {
+	// The Hungarian algorithm minimizes cost, so we minimize negative overlap.
+	// Arrays are 1-indexed, with 0 as a placeholder for "unassigned".
+	n := len(overlap)
+	rowPotential := make([]int, n+1)
+	columnPotential := make([]int, n+1)
+	rowOfColumn := make([]int, n+1)
+	previousColumn := make([]int, n+1)
+	for row := 1; row <= n; row++ {
+		rowOfColumn[0] = row
+		column := 0
+		minSlack := make([]int, n+1)
+		used := make([]bool, n+1)
+		for j := range minSlack {
+			minSlack[j] = int(^uint(0) >> 1)
+		}
+		for {
+			used[column] = true
+			currentRow := rowOfColumn[column]
+			delta := int(^uint(0) >> 1)
+			nextColumn := 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				slack := -overlap[currentRow-1][j-1] - rowPotential[currentRow] - columnPotential[j]
+				if slack < minSlack[j] {
+					minSlack[j] = slack
+					previousColumn[j] = column
+				}
+				if minSlack[j] < delta {
+					delta = minSlack[j]
+					nextColumn = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					rowPotential[rowOfColumn[j]] += delta
+					columnPotential[j] -= delta
+				} else {
+					minSlack[j] -= delta
+				}
+			}
+			column = nextColumn
+			if rowOfColumn[column] == 0 {
+				break
+			}
+		}
+		// Flip the assignments along the path we found
+		for column != 0 {
+			previous := previousColumn[column]
+			rowOfColumn[column] = rowOfColumn[previous]
+			column = previous
+		}
+	}
+
+	assignment := make([]int, n)
+	for column := 1; column <= n; column++ {
+		assignment[rowOfColumn[column]-1] = column - 1
+	}
+	return assignment
+}
+
+// alignTeams returns a copy of players whose team numbers have been changed to
+// best match the teams in reference.
+func alignTeams(reference []Player, players []Player) []Player {
+	// overlap[b][a] counts the players on team b in players and team a in
+	// reference, so we find the reference team for each of our teams
+	overlap := teamOverlap(players, reference)
+	assignment := maximumOverlapAssignment(overlap)
+	aligned := make([]Player, len(players))
+	copy(aligned, players)
+	for i := range aligned {
+		aligned[i].team = uint8(assignment[aligned[i].team])
+	}
+	return aligned
+}
+
+// teamCrossover combines the two given solutions by inheriting whole teams.
+//
+// After lining solution2's teams up with solution1's, each team is taken from
+// one parent at random. Players whose team in both parents went to the other
+// parent stay with their team from solution2.
+func teamCrossover(solution1 Solution, solution2 Solution) []Player {
+	aligned := alignTeams(solution1.players, solution2.players)
+	fromSolution2 := make([]bool, numTeams)
+	for team := range fromSolution2 {
+		fromSolution2[team] = rand.Intn(2) == 0
+	}
+
+	newPlayers := make([]Player, len(solution1.players))
+	copy(newPlayers, solution1.players)
+	for i := range newPlayers {
+		switch {
+		case fromSolution2[aligned[i].team]:
+			newPlayers[i].team = aligned[i].team
+		case !fromSolution2[solution1.players[i].team]:
+			newPlayers[i].team = solution1.players[i].team
+		default:
+			// Neither parent's team for this player was inherited
+			newPlayers[i].team = aligned[i].team
+		}
+	}
+	return newPlayers
+}
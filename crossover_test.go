@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaximumOverlapAssignment(t *testing.T) {
+	overlap := [][]int{
+		{0, 5, 1},
+		{4, 0, 3},
+		{3, 1, 0},
+	}
+	assert.Equal(t, []int{1, 2, 0}, maximumOverlapAssignment(overlap))
+
+	// Greedily taking the largest overlap (row 0 to column 0) isn't the best
+	overlap = [][]int{
+		{9, 8},
+		{8, 1},
+	}
+	assert.Equal(t, []int{1, 0}, maximumOverlapAssignment(overlap))
+}
+
+// relabeledCopy returns a copy of players with every team number rotated
+func relabeledCopy(players []Player) []Player {
+	relabeled := make([]Player, len(players))
+	copy(relabeled, players)
+	for i := range relabeled {
+		relabeled[i].team = uint8((int(relabeled[i].team) + 1) % numTeams)
+	}
+	return relabeled
+}
+
+func TestAlignTeams(t *testing.T) {
+	players := makeRandomPlayers(30)
+	relabeled := relabeledCopy(players)
+	assert.Equal(t, players, alignTeams(players, relabeled))
+	// The input isn't modified
+	assert.NotEqual(t, players, relabeled)
+}
+
+func TestTeamCrossover(t *testing.T) {
+	players := makeRandomPlayers(30)
+	solution1 := Solution{players, 0}
+	solution2 := Solution{relabeledCopy(players), 0}
+	// The parents are the same solution, so the child is too
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, players, teamCrossover(solution1, solution2))
+	}
+}
+
+// benchmarkCrossover runs a small single-threaded genetic algorithm using the
+// given crossover, reporting the best score reached.
+func benchmarkCrossover(b *testing.B, crossoverFunction func(Solution, Solution) []Player) {
+	const numGenerations = 50
+	originalCrossover := crossover
+	crossover = crossoverFunction
+	defer func() { crossover = originalCrossover }()
+
+	players := loadSamplePlayers()
+	index := newRosterIndex(players)
+	totalScore := Score(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rand.Seed(int64(i))
+		parents := make([]Solution, numParents)
+		for j := range parents {
+			parentPlayers := make([]Player, len(players))
+			copy(parentPlayers, players)
+			randomizeTeams(parentPlayers)
+			parents[j] = Solution{parentPlayers, newScoringEngine(index, parentPlayers).score()}
+		}
+		for generation := 0; generation < numGenerations; generation++ {
+			parents = breedGeneration(index, parents, 0)
+		}
+		totalScore += parents[0].score
+	}
+	b.ReportMetric(float64(totalScore)/float64(b.N), "score")
+}
+
+func BenchmarkTwoPointCrossover(b *testing.B) {
+	benchmarkCrossover(b, twoPointCrossover)
+}
+
+func BenchmarkTeamCrossover(b *testing.B) {
+	benchmarkCrossover(b, teamCrossover)
+}
@@ -0,0 +1,64 @@
+// Track how different the parent solutions are from each other, and shake
+// things up when they've all converged on the same solution.
+
+package main
+
+import "sort"
+
+type diversitySettings struct {
+	// restart when the average distance between parents falls below this. 0
+	// means never restart.
+	minDiversity float64
+	// number of best parents to keep through a restart
+	numElite int
+}
+
+// solutionDistance counts the players on different teams in the two
+// solutions, once their teams are lined up with each other
+func solutionDistance(players1 []Player, players2 []Player) int {
+	aligned := alignTeams(players1, players2)
+	distance := 0
+	for i := range players1 {
+		if players1[i].team != aligned[i].team {
+			distance += 1
+		}
+	}
+	return distance
+}
+
+// PopulationDiversity returns the average distance between each pair of
+// solutions
+func PopulationDiversity(solutions []Solution) float64 {
+	totalDistance, numPairs := 0, 0
+	for i := range solutions {
+		for j := i + 1; j < len(solutions); j++ {
+			totalDistance += solutionDistance(solutions[i].players, solutions[j].players)
+			numPairs += 1
+		}
+	}
+	if numPairs == 0 {
+		return 0
+	}
+	return float64(totalDistance) / float64(numPairs)
+}
+
+// maintain replaces all but the best numElite parents with random solutions if
+// the parents have become too alike. The parents must be sorted by score, and
+// they're sorted again after a restart.
+//
+// Returns the diversity of the parents before any restart, and whether or not
+// we restarted.
+func (settings diversitySettings) maintain(parents []Solution) (float64, bool) {
+	diversity := PopulationDiversity(parents)
+	if diversity >= settings.minDiversity || settings.numElite >= len(parents) {
+		return diversity, false
+	}
+	numElite := settings.numElite
+	if numElite < 1 {
+		numElite = 1
+	}
+	immigrants := randomSolutions(parents[0].players, len(parents)-numElite)
+	copy(parents[numElite:], immigrants)
+	sort.Sort(ByScore(parents))
+	return diversity, true
+}
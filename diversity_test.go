@@ -0,0 +1,38 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolutionDistance(t *testing.T) {
+	players := makeRandomPlayers(30)
+	assert.Equal(t, 0, solutionDistance(players, relabeledCopy(players)))
+
+	moved := relabeledCopy(players)
+	moved[0].team = uint8((int(moved[0].team) + 1) % numTeams)
+	assert.Equal(t, 1, solutionDistance(players, moved))
+}
+
+func TestMaintainDiversity(t *testing.T) {
+	players := makeRandomPlayers(30)
+	parents := make([]Solution, numParents)
+	for i := range parents {
+		parents[i] = Solution{relabeledCopy(players), Score(i)}
+	}
+	assert.Equal(t, 0.0, PopulationDiversity(parents))
+
+	// Never restart with a minimum of 0
+	_, restarted := diversitySettings{0, 2}.maintain(parents)
+	assert.False(t, restarted)
+
+	diversity, restarted := diversitySettings{1, 2}.maintain(parents)
+	assert.Equal(t, 0.0, diversity)
+	assert.True(t, restarted)
+	assert.Equal(t, Score(0), parents[0].score)
+	assert.Equal(t, Score(1), parents[1].score)
+	assert.True(t, sort.IsSorted(ByScore(parents)))
+	assert.True(t, PopulationDiversity(parents) > 1)
+}
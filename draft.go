@@ -0,0 +1,159 @@
+// Simulated captains' drafts, to compare with the optimized rosters. Teams take
+// turns picking players, in a snake or linear order, until everybody's picked.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// draftSettings are how to run the draft
+type draftSettings struct {
+	// "snake" reverses the order every round, "linear" keeps it the same
+	order string
+	// "best" picks the best available player. "needs" picks the best available
+	// player of a gender the team needs, skips teams that are already full, and
+	// brings along the baggages of each pick.
+	strategy string
+}
+
+// pickingOrder returns the order the teams pick in, in the given round
+func (settings draftSettings) pickingOrder(round int) []int {
+	teams := make([]int, numTeams)
+	for i := range teams {
+		teams[i] = i
+		if settings.order == "snake" && round%2 == 1 {
+			teams[i] = numTeams - 1 - i
+		}
+	}
+	return teams
+}
+
+// draftState is the state of the draft so far
+type draftState struct {
+	players []Player
+	// indexes of the players from best to worst
+	byRating []int
+	picked   []bool
+	// number of players, and of each gender, on each team
+	teamSizes   []int
+	genderSizes [][2]int
+	numPicked   int
+}
+
+// fairShare is how many of numPlayers each team should end up with
+func fairShare(numPlayers int) int {
+	return int(math.Ceil(float64(numPlayers) / numTeams))
+}
+
+// pick puts the player on the team
+func (state *draftState) pick(player int, team int) {
+	state.players[player].team = uint8(team)
+	state.picked[player] = true
+	state.teamSizes[team] += 1
+	state.genderSizes[team][state.players[player].gender] += 1
+	state.numPicked += 1
+}
+
+// bestAvailable returns the best player not yet picked who passes the filter,
+// or -1 if there are none
+func (state *draftState) bestAvailable(filter PlayerFilter) int {
+	for _, i := range state.byRating {
+		if !state.picked[i] && (filter == nil || filter(state.players[i])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// takeTurn makes the team's pick, following the strategy
+func (state *draftState) takeTurn(team int, strategy string) {
+	if strategy != "needs" {
+		state.pick(state.bestAvailable(nil), team)
+		return
+	}
+	// Full teams skip their pick, unless every team is full
+	if state.teamSizes[team] >= fairShare(len(state.players)) {
+		for _, size := range state.teamSizes {
+			if size < fairShare(len(state.players)) {
+				return
+			}
+		}
+	}
+	player := -1
+	for _, gender := range []Gender{Male, Female} {
+		share := fairShare(len(Filter(state.players, func(p Player) bool {
+			return p.gender == gender
+		})))
+		if state.genderSizes[team][gender] >= share {
+			continue
+		}
+		candidate := state.bestAvailable(func(p Player) bool { return p.gender == gender })
+		if candidate >= 0 && (player < 0 ||
+			state.players[candidate].rating > state.players[player].rating) {
+			player = candidate
+		}
+	}
+	if player < 0 {
+		player = state.bestAvailable(nil)
+	}
+	state.pick(player, team)
+	// Baggages come along with the pick
+	for _, baggage := range state.players[player].baggages {
+		for i := range state.players {
+			if state.players[i].name == baggage && !state.picked[i] {
+				state.pick(i, team)
+			}
+		}
+	}
+}
+
+// Draft simulates a draft of the players, returning the rosters it makes
+func Draft(players []Player, settings draftSettings) Solution {
+	state := draftState{
+		players:     make([]Player, len(players)),
+		byRating:    make([]int, len(players)),
+		picked:      make([]bool, len(players)),
+		teamSizes:   make([]int, numTeams),
+		genderSizes: make([][2]int, numTeams),
+	}
+	copy(state.players, players)
+	for i := range state.byRating {
+		state.byRating[i] = i
+	}
+	sort.SliceStable(state.byRating, func(a, b int) bool {
+		return players[state.byRating[a]].rating > players[state.byRating[b]].rating
+	})
+	for round := 0; state.numPicked < len(players); round++ {
+		for _, team := range settings.pickingOrder(round) {
+			if state.numPicked == len(players) {
+				break
+			}
+			state.takeTurn(team, settings.strategy)
+		}
+	}
+	score, _ := ScoreSolution(state.players)
+	return Solution{state.players, score}
+}
+
+// PrintDraftComparison shows the weighted score of each criterion for the
+// drafted and the optimized rosters, side by side
+func PrintDraftComparison(drafted Solution, optimized Solution) {
+	draftedTeams := splitIntoTeams(drafted.players)
+	optimizedTeams := splitIntoTeams(optimized.players)
+	writer := new(tabwriter.Writer)
+	writer.Init(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "Criterion\tDraft\tOptimized")
+	for _, criterion := range criteriaToScore {
+		_, draftedScore := criterion.weigh(criterion.rawScore(draftedTeams))
+		_, optimizedScore := criterion.weigh(criterion.rawScore(optimizedTeams))
+		fmt.Fprintf(writer, "%s\t%.02f\t%.02f\n",
+			criterion.name, draftedScore, optimizedScore)
+	}
+	fmt.Fprintf(writer, "Total score\t%.02f\t%.02f\n", drafted.score, optimized.score)
+	writer.Flush()
+}
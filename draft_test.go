@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeDraftPlayers makes players rated from numPlayers down to 1
+func makeDraftPlayers(numPlayers int) []Player {
+	players := make([]Player, numPlayers)
+	for i := range players {
+		players[i] = Player{name: Name{string(rune('A' + i)), "Player"},
+			rating: float32(numPlayers - i), gender: Male, baggages: []Name{}}
+	}
+	return players
+}
+
+func TestPickingOrder(t *testing.T) {
+	snake := draftSettings{"snake", "best"}
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, snake.pickingOrder(0))
+	assert.Equal(t, []int{5, 4, 3, 2, 1, 0}, snake.pickingOrder(1))
+	linear := draftSettings{"linear", "best"}
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, linear.pickingOrder(1))
+}
+
+func TestDraftBestAvailable(t *testing.T) {
+	players := makeDraftPlayers(2 * numTeams)
+	drafted := Draft(players, draftSettings{"snake", "best"})
+	// The first team picks first, then last
+	assert.Equal(t, uint8(0), drafted.players[0].team)
+	assert.Equal(t, uint8(0), drafted.players[2*numTeams-1].team)
+	assert.Equal(t, uint8(5), drafted.players[numTeams-1].team)
+	assert.Equal(t, uint8(5), drafted.players[numTeams].team)
+	score, _ := ScoreSolution(drafted.players)
+	assert.Equal(t, score, drafted.score)
+	// The players passed in are left alone
+	assert.Equal(t, uint8(0), players[numTeams].team)
+
+	drafted = Draft(players, draftSettings{"linear", "best"})
+	assert.Equal(t, uint8(0), drafted.players[numTeams].team)
+}
+
+func TestDraftNeeds(t *testing.T) {
+	players := makeDraftPlayers(2 * numTeams)
+	// The six best are male, so each team needs a female with its next pick
+	for i := numTeams; i < len(players); i++ {
+		players[i].gender = Female
+	}
+	// The best player brings along the worst
+	players[0].baggages = []Name{players[len(players)-1].name}
+	drafted := Draft(players, draftSettings{"snake", "needs"})
+	teams := splitIntoTeams(drafted.players)
+	for _, team := range teams {
+		assert.Equal(t, 2, len(team.players))
+		assert.Equal(t, 1, len(Filter(team.players, IsFemale)))
+	}
+	assert.Equal(t, drafted.players[0].team, drafted.players[len(players)-1].team)
+}
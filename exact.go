@@ -0,0 +1,366 @@
+// Exact solver for small leagues, using branch and bound.
+//
+// Baggages are kept together, and the number of players, males and females on
+// each team are kept within one of each other. Within those constraints we
+// minimize a linear measure of rating balance: the total distance of each
+// team's summed rating from what it would be if all its players were average.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// exactUnit is a group of players who must be placed on the same team
+type exactUnit struct {
+	players []int // indices into the list of players
+	// sum of the players' ratings, less the average rating for each player
+	rating         float64
+	males, females int
+}
+
+type exactTeam struct {
+	size, males, females int
+	// sum of the team's ratings, less the average rating for each player
+	rating float64
+}
+
+// ExactResult describes the outcome of the exact solver
+type ExactResult struct {
+	solution Solution
+	// objective is the rating deviation of the solution. lowerBound is the
+	// smallest deviation any solution could have; if they're equal, proven is
+	// true.
+	objective, lowerBound float64
+	proven                bool
+	numNodes              int64
+}
+
+type exactSolver struct {
+	units []exactUnit
+
+	minSize, maxSize       int
+	minMales, maxMales     int
+	minFemales, maxFemales int
+
+	// for the units from each depth on: the number of players, males and
+	// females left to place, and the prefix sums of their (less average)
+	// ratings, highest first
+	remainingPlayers, remainingMales, remainingFemales []int
+	remainingRatingSums                                [][]float64
+
+	teams      []exactTeam
+	assignment []int // the team of each unit
+	// best complete assignment found so far
+	bestAssignment []int
+	bestObjective  float64
+
+	numNodes   int64
+	deadline   time.Time
+	doneSignal <-chan os.Signal
+	stopped    bool
+	// smallest bound of the parts of the search we didn't get to
+	unexploredBound float64
+}
+
+// averageRating of all the players
+func averageRating(players []Player) float64 {
+	total := 0.0
+	for _, player := range players {
+		total += float64(player.rating)
+	}
+	return total / float64(len(players))
+}
+
+// RatingDeviation is the exact solver's objective: the total distance of each
+// team's summed rating from what it would be if all its players were average
+func RatingDeviation(players []Player) float64 {
+	average := averageRating(players)
+	teamRatings := make([]float64, numTeams)
+	for _, player := range players {
+		teamRatings[player.team] += float64(player.rating) - average
+	}
+	deviation := 0.0
+	for _, rating := range teamRatings {
+		deviation += math.Abs(rating)
+	}
+	return deviation
+}
+
+// newExactSolver groups the players into units and works out the constraints
+func newExactSolver(players []Player) (*exactSolver, error) {
+	solver := exactSolver{
+		teams:           make([]exactTeam, numTeams),
+		bestObjective:   math.Inf(1),
+		unexploredBound: math.Inf(1),
+	}
+
+	// Every baggage group is a unit, as is every player without baggages
+	groups := connectedGroups(len(players), findBaggageLinks(players))
+	inGroup := make([]bool, len(players))
+	for _, group := range groups {
+		for _, index := range group {
+			inGroup[index] = true
+		}
+	}
+	for i := range players {
+		if !inGroup[i] {
+			groups = append(groups, []int{i})
+		}
+	}
+
+	average := averageRating(players)
+	numMales, numFemales := 0, 0
+	for _, group := range groups {
+		unit := exactUnit{players: group}
+		for _, index := range group {
+			unit.rating += float64(players[index].rating) - average
+			if IsMale(players[index]) {
+				unit.males += 1
+			} else {
+				unit.females += 1
+			}
+		}
+		numMales += unit.males
+		numFemales += unit.females
+		solver.units = append(solver.units, unit)
+	}
+	solver.minSize, solver.maxSize = len(players)/numTeams, (len(players)+numTeams-1)/numTeams
+	solver.minMales, solver.maxMales = numMales/numTeams, (numMales+numTeams-1)/numTeams
+	solver.minFemales, solver.maxFemales =
+		numFemales/numTeams, (numFemales+numTeams-1)/numTeams
+	for _, unit := range solver.units {
+		if len(unit.players) > solver.maxSize || unit.males > solver.maxMales ||
+			unit.females > solver.maxFemales {
+			return nil, fmt.Errorf("a baggage group of %d players is too large for a team",
+				len(unit.players))
+		}
+	}
+
+	// Place the hardest units first: the largest, then the highest rated
+	sort.SliceStable(solver.units, func(a, b int) bool {
+		unitA, unitB := solver.units[a], solver.units[b]
+		if len(unitA.players) != len(unitB.players) {
+			return len(unitA.players) > len(unitB.players)
+		}
+		return math.Abs(unitA.rating) > math.Abs(unitB.rating)
+	})
+	numUnits := len(solver.units)
+	solver.remainingPlayers = make([]int, numUnits+1)
+	solver.remainingMales = make([]int, numUnits+1)
+	solver.remainingFemales = make([]int, numUnits+1)
+	solver.remainingRatingSums = make([][]float64, numUnits+1)
+	for depth := 0; depth <= numUnits; depth++ {
+		ratings := []float64{}
+		for _, unit := range solver.units[depth:] {
+			solver.remainingMales[depth] += unit.males
+			solver.remainingFemales[depth] += unit.females
+			for _, index := range unit.players {
+				ratings = append(ratings, float64(players[index].rating)-average)
+			}
+		}
+		solver.remainingPlayers[depth] = len(ratings)
+		sort.Sort(sort.Reverse(sort.Float64Slice(ratings)))
+		sums := make([]float64, len(ratings)+1)
+		for i, rating := range ratings {
+			sums[i+1] = sums[i] + rating
+		}
+		solver.remainingRatingSums[depth] = sums
+	}
+	solver.assignment = make([]int, numUnits)
+	return &solver, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// feasible returns whether the teams can still be filled out to their minimums
+// by the units from depth on
+func (s *exactSolver) feasible(depth int) bool {
+	playersNeeded, malesNeeded, femalesNeeded := 0, 0, 0
+	for _, team := range s.teams {
+		playersNeeded += maxInt(0, s.minSize-team.size)
+		malesNeeded += maxInt(0, s.minMales-team.males)
+		femalesNeeded += maxInt(0, s.minFemales-team.females)
+	}
+	return playersNeeded <= s.remainingPlayers[depth] &&
+		malesNeeded <= s.remainingMales[depth] &&
+		femalesNeeded <= s.remainingFemales[depth]
+}
+
+// bound returns the smallest objective that any completion of the teams, using
+// the units from depth on, could have.
+//
+// Each team still needs some players, and has room for some more. Taking the
+// lowest and highest ratings left for any number of players in between bounds
+// how far above or below average the team must end up.
+func (s *exactSolver) bound(depth int) float64 {
+	sums := s.remainingRatingSums[depth]
+	numRemaining := len(sums) - 1
+	totalAbove, totalBelow, totalEither := 0.0, 0.0, 0.0
+	for _, team := range s.teams {
+		room := minInt(s.maxSize-team.size, numRemaining)
+		needed := minInt(maxInt(0, s.minSize-team.size), numRemaining)
+		minGain, maxGain := math.Inf(1), math.Inf(-1)
+		for numPlayers := needed; numPlayers <= room; numPlayers++ {
+			highest := sums[numPlayers]
+			lowest := sums[numRemaining] - sums[numRemaining-numPlayers]
+			maxGain = math.Max(maxGain, highest)
+			minGain = math.Min(minGain, lowest)
+		}
+		above := math.Max(0, team.rating+minGain)
+		below := math.Max(0, -team.rating-maxGain)
+		totalAbove += above
+		totalBelow += below
+		totalEither += math.Max(above, below)
+	}
+	// The amounts above and below average always balance out in the end
+	return math.Max(totalEither, 2*math.Max(totalAbove, totalBelow))
+}
+
+func (s *exactSolver) objective() float64 {
+	deviation := 0.0
+	for _, team := range s.teams {
+		deviation += math.Abs(team.rating)
+	}
+	return deviation
+}
+
+func (s *exactSolver) fits(unit exactUnit, team exactTeam) bool {
+	return team.size+len(unit.players) <= s.maxSize &&
+		team.males+unit.males <= s.maxMales &&
+		team.females+unit.females <= s.maxFemales
+}
+
+func (s *exactSolver) place(unit exactUnit, team int, sign int) {
+	s.teams[team].size += sign * len(unit.players)
+	s.teams[team].males += sign * unit.males
+	s.teams[team].females += sign * unit.females
+	s.teams[team].rating += float64(sign) * unit.rating
+}
+
+// timeToStop checks the time limit and exit signal every so often
+func (s *exactSolver) timeToStop() bool {
+	if s.stopped {
+		return true
+	}
+	if s.numNodes%1024 != 0 {
+		return false
+	}
+	select {
+	case <-s.doneSignal:
+		fmt.Println("Exit signal received")
+		s.stopped = true
+	default:
+		s.stopped = time.Now().After(s.deadline)
+	}
+	return s.stopped
+}
+
+// search places the unit at depth on each team in turn, then recurses
+func (s *exactSolver) search(depth int) {
+	s.numNodes += 1
+	if depth == len(s.units) {
+		if objective := s.objective(); objective < s.bestObjective {
+			s.bestObjective = objective
+			s.bestAssignment = append([]int{}, s.assignment...)
+			newLog.Debug("Exact solver found rating deviation %.02f after %d nodes",
+				objective, s.numNodes)
+		}
+		return
+	}
+
+	// Teams in the same state (like the empty ones) are interchangeable, so only
+	// try one of them. Try the teams this unit brings closest to average first,
+	// to find good solutions early.
+	unit := s.units[depth]
+	candidates := []int{}
+	for team := range s.teams {
+		duplicate := false
+		for _, candidate := range candidates {
+			if s.teams[candidate] == s.teams[team] {
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			candidates = append(candidates, team)
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return math.Abs(s.teams[candidates[a]].rating+unit.rating) <
+			math.Abs(s.teams[candidates[b]].rating+unit.rating)
+	})
+
+	for _, team := range candidates {
+		if !s.fits(unit, s.teams[team]) {
+			continue
+		}
+		s.place(unit, team, 1)
+		if s.feasible(depth + 1) {
+			bound := s.bound(depth + 1)
+			if bound < s.bestObjective-1e-9 {
+				if s.timeToStop() {
+					// Remember how good this unexplored branch could have been
+					s.unexploredBound = math.Min(s.unexploredBound, bound)
+				} else {
+					s.assignment[depth] = team
+					s.search(depth + 1)
+				}
+			}
+		}
+		s.place(unit, team, -1)
+	}
+}
+
+// SolveExactly searches every assignment of players to teams (skipping any
+// that can't beat the best found so far) for the one with the smallest
+// RatingDeviation.
+//
+// If the time limit passes or we're told to stop, returns the best solution
+// found along with a lower bound for the true optimum.
+func SolveExactly(players []Player, timeLimit time.Duration,
+	doneSignal <-chan os.Signal) (ExactResult, error) {
+	solver, err := newExactSolver(players)
+	if err != nil {
+		return ExactResult{}, err
+	}
+	solver.deadline = time.Now().Add(timeLimit)
+	solver.doneSignal = doneSignal
+	solver.search(0)
+	if solver.bestAssignment == nil {
+		return ExactResult{}, fmt.Errorf(
+			"no assignment keeps baggages together and teams balanced")
+	}
+
+	solutionPlayers := make([]Player, len(players))
+	copy(solutionPlayers, players)
+	for i, unit := range solver.units {
+		for _, index := range unit.players {
+			solutionPlayers[index].team = uint8(solver.bestAssignment[i])
+		}
+	}
+	score, _ := ScoreSolution(solutionPlayers)
+	result := ExactResult{
+		solution:   Solution{solutionPlayers, score},
+		objective:  solver.bestObjective,
+		lowerBound: math.Min(solver.bestObjective, solver.unexploredBound),
+		numNodes:   solver.numNodes,
+	}
+	result.proven = !solver.stopped || result.lowerBound >= result.objective
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveExactly(t *testing.T) {
+	// Two players per team, who can be paired up to make even teams
+	players := make([]Player, numTeams*2)
+	for i := 0; i < numTeams; i++ {
+		players[2*i] = Player{name: Name{string(rune('A' + 2*i)), "Player"},
+			rating: float32(10 * i), gender: Male, baggages: []Name{}}
+		players[2*i+1] = Player{name: Name{string(rune('B' + 2*i)), "Player"},
+			rating: float32(100 - 10*i), gender: Female, baggages: []Name{}}
+	}
+	players[0].baggages = []Name{players[1].name}
+
+	result, err := SolveExactly(players, time.Minute, make(chan os.Signal))
+	assert.Nil(t, err)
+	assert.True(t, result.proven)
+	assert.InDelta(t, 0, result.objective, 1e-6)
+	assert.InDelta(t, 0, RatingDeviation(result.solution.players), 1e-3)
+	assert.Equal(t, result.solution.players[0].team, result.solution.players[1].team)
+	for _, team := range splitIntoTeams(result.solution.players) {
+		assert.Equal(t, 1, len(Filter(team.players, IsMale)))
+		assert.Equal(t, 1, len(Filter(team.players, IsFemale)))
+	}
+}
+
+func TestSolveExactlyWithoutTime(t *testing.T) {
+	players := makeRandomPlayers(30)
+	for i := range players {
+		players[i].baggages = []Name{}
+	}
+	result, err := SolveExactly(players, 0, make(chan os.Signal))
+	assert.Nil(t, err)
+	assert.True(t, result.lowerBound <= result.objective)
+	assert.InDelta(t, result.objective, RatingDeviation(result.solution.players), 1e-3)
+}
+
+func TestSolveExactlyTooLargeGroup(t *testing.T) {
+	players := makeChainPlayers(numTeams * 2)
+	_, err := SolveExactly(players, time.Minute, make(chan os.Signal))
+	assert.NotNil(t, err)
+}
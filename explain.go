@@ -0,0 +1,115 @@
+// Explain why each player ended up on the team they did
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// weightedScores returns the weighted score of each of the criteriaToScore
+func weightedScores(players []Player) []Score {
+	teams := splitIntoTeams(players)
+	scores := make([]Score, len(criteriaToScore))
+	for i, criterion := range criteriaToScore {
+		_, _, scores[i], _ = criterion.analyze(teams)
+	}
+	return scores
+}
+
+// ExplainPlacement calculates how much each criterion's weighted score would
+// change if the given player moved to each team.
+//
+// Returns a slice for each team, holding the change for each of the
+// criteriaToScore. The player's current team has no change.
+func ExplainPlacement(players []Player, playerIndex int) [][]Score {
+	currentScores := weightedScores(players)
+	movedPlayers := make([]Player, len(players))
+	copy(movedPlayers, players)
+
+	deltas := make([][]Score, numTeams)
+	for team := range deltas {
+		deltas[team] = make([]Score, len(criteriaToScore))
+		if team == int(players[playerIndex].team) {
+			continue
+		}
+		movedPlayers[playerIndex].team = uint8(team)
+		for i, score := range weightedScores(movedPlayers) {
+			deltas[team][i] = score - currentScores[i]
+		}
+	}
+	return deltas
+}
+
+// keptInPlaceBy returns the index of each criterion that would get worse no
+// matter which team the player moved to.
+func keptInPlaceBy(deltas [][]Score, currentTeam int) (criteria []int) {
+	for i := range criteriaToScore {
+		keeping := true
+		for team := range deltas {
+			if team != currentTeam && deltas[team][i] <= 0 {
+				keeping = false
+			}
+		}
+		if keeping {
+			criteria = append(criteria, i)
+		}
+	}
+	return
+}
+
+// describeDeltas lists each criterion that changed, most costly first
+func describeDeltas(deltas []Score) string {
+	indices := make([]int, 0, len(deltas))
+	for i, delta := range deltas {
+		// Skip anything that would round to zero when printed
+		if delta >= 0.005 || delta <= -0.005 {
+			indices = append(indices, i)
+		}
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		return deltas[indices[a]] > deltas[indices[b]]
+	})
+	descriptions := make([]string, len(indices))
+	for i, index := range indices {
+		descriptions[i] = fmt.Sprintf("%s %+.02f",
+			criteriaToScore[index].name, deltas[index])
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// PrintPlacementExplanations prints, for each player, how the score would
+// change if they moved to each other team, and which criteria kept them on
+// their current team.
+func PrintPlacementExplanations(solution Solution) {
+	writer := new(tabwriter.Writer)
+	writer.Init(os.Stdout, 0, 0, 1, ' ', 0)
+	for i, player := range solution.players {
+		currentTeam := int(player.team)
+		deltas := ExplainPlacement(solution.players, i)
+		fmt.Fprintf(writer, "%v is on team %d\n", player.name, currentTeam+1)
+		for team, teamDeltas := range deltas {
+			if team == currentTeam {
+				continue
+			}
+			total := Score(0)
+			for _, delta := range teamDeltas {
+				total += delta
+			}
+			fmt.Fprintf(writer, "  Moving to team %d:\tScore %+.02f\t(%s)\n",
+				team+1, total, describeDeltas(teamDeltas))
+		}
+		names := []string{}
+		for _, criterionIndex := range keptInPlaceBy(deltas, currentTeam) {
+			names = append(names, criteriaToScore[criterionIndex].name)
+		}
+		if len(names) == 0 {
+			names = append(names, "no single criterion")
+		}
+		fmt.Fprintf(writer, "  Kept in place by: %s\n", strings.Join(names, ", "))
+	}
+	writer.Flush()
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainPlacement(t *testing.T) {
+	players := make([]Player, numTeams*2)
+	for i := range players {
+		players[i] = Player{name: Name{string(rune('A' + i)), "Player"}, rating: 50,
+			gender: Male, team: uint8(i % numTeams), baggages: []Name{}}
+	}
+	// A and G are baggages, and on the same team
+	players[0].baggages = []Name{players[numTeams].name}
+
+	deltas := ExplainPlacement(players, 0)
+	assert.Equal(t, numTeams, len(deltas))
+	for team, teamDeltas := range deltas {
+		assert.Equal(t, len(criteriaToScore), len(teamDeltas))
+		if team == 0 {
+			for _, delta := range teamDeltas {
+				assert.Equal(t, Score(0), delta)
+			}
+			continue
+		}
+		// Moving away breaks the baggage, and the ratings are all the same
+		assert.True(t, teamDeltas[0] > 0)
+		assert.Equal(t, Score(0), teamDeltas[4])
+	}
+	assert.Contains(t, keptInPlaceBy(deltas, 0), 0)
+
+	// The player's move shouldn't leak into the input
+	assert.Equal(t, uint8(0), players[0].team)
+}
@@ -3,7 +3,11 @@
 package main
 
 import (
+	"encoding/csv"
+	"fmt"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/topher200/baseutil"
 )
@@ -18,8 +22,9 @@ func ParsePlayers(inputFilename string) []Player {
 		baseutil.Check(err)
 		rating, err := strconv.ParseFloat(row["Balanced Rating"], 32)
 		baseutil.Check(err)
-		players[i] = Player{
-			Name{firstName, lastName}, float32(rating), gender, uint8(0), []Name{}}
+		players[i] = Player{name: Name{firstName, lastName}, rating: float32(rating),
+			gender: gender, team: uint8(0), baggages: []Name{}, attributes: row,
+			roles: parseList(row["Role"]), positions: parsePositions(row["Positions"])}
 	}
 	return players
 }
@@ -36,3 +41,141 @@ func ParseBaggages(inputFilename string, players []Player) {
 			playerPointer.baggages[len(playerPointer.baggages)-1], playerPointer.String())
 	}
 }
+
+// ParseScales reads the scale of each criterion, for fixed normalization
+func ParseScales(inputFilename string) map[string]Score {
+	scales := make(map[string]Score)
+	for _, row := range baseutil.MapReader(inputFilename) {
+		scale, err := strconv.ParseFloat(row["Scale"], 64)
+		baseutil.Check(err)
+		scales[row["Criterion"]] = Score(scale)
+	}
+	return scales
+}
+
+// ParseCriteria reads the criteria to score with. Each row has the criterion's
+// Name, the kind of Criterion, a Filter, the number of Top Players to look at
+// (blank for all), a Weight and an Argument for the kind of criterion.
+//
+// Returns error if a row is invalid.
+func ParseCriteria(inputFilename string) ([]criterion, error) {
+	criteria := []criterion{}
+	for _, row := range baseutil.MapReader(inputFilename) {
+		filter, err := StringToFilter(row["Filter"])
+		if err != nil {
+			return nil, err
+		}
+		numPlayers := 0
+		if row["Top Players"] != "" {
+			numPlayers, err = strconv.Atoi(row["Top Players"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid number of top players '%s'", row["Top Players"])
+			}
+		}
+		weight, err := strconv.Atoi(row["Weight"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight '%s'", row["Weight"])
+		}
+		c, err := newCriterion(
+			row["Name"], row["Criterion"], row["Argument"], filter, numPlayers, weight)
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, c)
+	}
+	return criteria, nil
+}
+
+// ParsePreviousRoster reads a past season's rosters: the First Name, Last Name
+// and Team of each player
+func ParsePreviousRoster(inputFilename string) map[Name]string {
+	teams := make(map[Name]string)
+	for _, row := range baseutil.MapReader(inputFilename) {
+		teams[Name{row["First Name"], row["Last Name"]}] = row["Team"]
+	}
+	return teams
+}
+
+// ParseRoleConstraints reads how many players of each Role a team should have,
+// with a Min and Max (either blank for no limit), as a criterion for each.
+//
+// Returns error if a row is invalid.
+func ParseRoleConstraints(inputFilename string) ([]criterion, error) {
+	criteria := []criterion{}
+	for _, row := range baseutil.MapReader(inputFilename) {
+		c, err := newCriterion(row["Role"]+" per team", "role",
+			fmt.Sprintf("%s:%s:%s", row["Role"], row["Min"], row["Max"]),
+			nil, 0, roleWeight)
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, c)
+	}
+	return criteria, nil
+}
+
+// ParsePositionQuotas reads how many players of each Position a team needs, as
+// its Count. Positions are in lower case.
+//
+// Returns error if a count is invalid.
+func ParsePositionQuotas(inputFilename string) (map[string]int, error) {
+	quotas := make(map[string]int)
+	for _, row := range baseutil.MapReader(inputFilename) {
+		count, err := strconv.Atoi(row["Count"])
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("invalid count '%s' for position '%s'",
+				row["Count"], row["Position"])
+		}
+		quotas[strings.ToLower(strings.TrimSpace(row["Position"]))] = count
+	}
+	return quotas, nil
+}
+
+// ParseAvailability reads the chance of each player attending each date. Each
+// row has a player's First Name and Last Name, and every other column is a
+// date, in order.
+//
+// Returns error if the file can't be read, or has an invalid availability.
+func ParseAvailability(inputFilename string) (availability, error) {
+	file, err := os.Open(inputFilename)
+	if err != nil {
+		return availability{}, err
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return availability{}, err
+	}
+	if len(rows) == 0 {
+		return availability{}, fmt.Errorf("%s is empty", inputFilename)
+	}
+	a := availability{chances: make(map[Name][]float64)}
+	firstName, lastName := -1, -1
+	dateColumns := []int{}
+	for i, column := range rows[0] {
+		switch column {
+		case "First Name":
+			firstName = i
+		case "Last Name":
+			lastName = i
+		default:
+			a.dates = append(a.dates, column)
+			dateColumns = append(dateColumns, i)
+		}
+	}
+	if firstName < 0 || lastName < 0 {
+		return availability{}, fmt.Errorf(
+			"%s needs \"First Name\" and \"Last Name\" columns", inputFilename)
+	}
+	for _, row := range rows[1:] {
+		chances := make([]float64, len(dateColumns))
+		for i, column := range dateColumns {
+			if chances[i], err = parseChance(row[column]); err != nil {
+				return availability{}, fmt.Errorf("%v for %s %s on %s", err,
+					row[firstName], row[lastName], a.dates[i])
+			}
+		}
+		a.chances[Name{row[firstName], row[lastName]}] = chances
+	}
+	return a, nil
+}
@@ -0,0 +1,152 @@
+// Island model genetic algorithm. Several populations evolve separately, each
+// on its own goroutine unless we're deterministic, with their best solutions
+// migrating between them every so often. Keeping populations apart slows down
+// premature convergence.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+type islandSettings struct {
+	numIslands int
+	// number of generations each island evolves between migrations
+	migrationInterval int
+	// number of each island's best solutions that migrate to the next island
+	migrationSize int
+}
+
+type island struct {
+	parents []Solution
+	// best score found by this island, and the generation it was found on
+	topScore          Score
+	topScoreRunNumber int
+	// number of times the island restarted after its parents converged
+	numRestarts int
+}
+
+// breedGeneration creates a new generation on the current goroutine, returning
+// the next parents chosen from the children and the best numElite parents.
+func breedGeneration(index *rosterIndex, parents []Solution, numElite int) []Solution {
+	newSolutions := make([]Solution, numSolutionsPerRun)
+	for i := range newSolutions {
+		newSolutions[i] = breed(
+			index, tournamentSelection(parents), tournamentSelection(parents))
+	}
+	return selectParents(parents, newSolutions, numElite)
+}
+
+// evolve runs the island for the given number of generations, starting at
+// generation runNumber.
+func (isle *island) evolve(index *rosterIndex, runNumber int, numGenerations int,
+	numElite int, diversity diversitySettings) {
+	for generation := 0; generation < numGenerations; generation++ {
+		isle.parents = breedGeneration(index, isle.parents, numElite)
+		if isle.parents[0].score < isle.topScore {
+			isle.topScore = isle.parents[0].score
+			isle.topScoreRunNumber = runNumber + generation
+		}
+		if _, restarted := diversity.maintain(isle.parents); restarted {
+			isle.numRestarts += 1
+		}
+	}
+}
+
+// migrate copies the best solutions of each island over the worst solutions
+// of the next island, around in a ring. An island whose top score is beaten by
+// its migrants counts it as found on run runNumber.
+func migrate(islands []*island, migrationSize int, runNumber int) {
+	migrants := make([][]Solution, len(islands))
+	for i, isle := range islands {
+		migrants[i] = append([]Solution{}, isle.parents[:migrationSize]...)
+	}
+	for i, isle := range islands {
+		from := migrants[(i+len(islands)-1)%len(islands)]
+		copy(isle.parents[len(isle.parents)-migrationSize:], from)
+		sort.Sort(ByScore(isle.parents))
+		if isle.parents[0].score < isle.topScore {
+			isle.topScore = isle.parents[0].score
+			isle.topScoreRunNumber = runNumber
+		}
+	}
+}
+
+// runIslands evolves separate populations of random solutions until none of
+// them find a better solution for a while, or we're told to stop.
+//
+// Returns the best solution found on any island. Each island's parents are
+// added to the hall of fame after every migration. With only one worker, the
+// islands evolve one after another, so the results are deterministic.
+func runIslands(index *rosterIndex, players []Player, settings islandSettings,
+	numWorkers int, numElite int, diversity diversitySettings, hall *hallOfFame,
+	doneSignal <-chan os.Signal) Solution {
+	if settings.migrationSize > numParents {
+		settings.migrationSize = numParents
+	}
+	if settings.migrationInterval < 1 {
+		settings.migrationInterval = 1
+	}
+	islands := make([]*island, settings.numIslands)
+	for i := range islands {
+		parents := randomSolutions(players, numParents)
+		sort.Sort(ByScore(parents))
+		islands[i] = &island{parents, parents[0].score, 0, 0}
+	}
+
+	numRunsCompleted := 0
+	for {
+		var waitGroup sync.WaitGroup
+		for _, isle := range islands {
+			if numWorkers == 1 {
+				isle.evolve(index, numRunsCompleted, settings.migrationInterval, numElite,
+					diversity)
+				continue
+			}
+			waitGroup.Add(1)
+			go func(isle *island) {
+				defer waitGroup.Done()
+				isle.evolve(index, numRunsCompleted, settings.migrationInterval, numElite,
+					diversity)
+			}(isle)
+		}
+		waitGroup.Wait()
+		numRunsCompleted += settings.migrationInterval
+		migrate(islands, settings.migrationSize, numRunsCompleted)
+
+		topScoreRunNumber := 0
+		for i, isle := range islands {
+			newLog.Debug("Island %d after run %d. Score: %.02f", i+1,
+				numRunsCompleted, isle.parents[0].score)
+			for _, solution := range isle.parents {
+				hall.add(solution)
+			}
+			if isle.topScoreRunNumber > topScoreRunNumber {
+				topScoreRunNumber = isle.topScoreRunNumber
+			}
+		}
+		if timeToClose(numRunsCompleted, topScoreRunNumber, doneSignal) {
+			break
+		}
+	}
+
+	// Migrants can carry an island's best solution elsewhere, so look at every
+	// island's current best
+	bestIsland := 0
+	for i, isle := range islands {
+		fmt.Printf("Island %d top score was %.02f, found on run #%d. Restarted %d times\n",
+			i+1, isle.topScore, isle.topScoreRunNumber, isle.numRestarts)
+		if isle.parents[0].score < islands[bestIsland].parents[0].score {
+			bestIsland = i
+		}
+	}
+	fmt.Printf("Exiting after %d runs. Island %d has the best solution\n",
+		numRunsCompleted, bestIsland+1)
+	if trackMutationStats {
+		fmt.Println(MutationStats())
+	}
+	return islands[bestIsland].parents[0]
+}
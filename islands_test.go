@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	islands := make([]*island, 3)
+	for i := range islands {
+		parents := make([]Solution, numParents)
+		for j := range parents {
+			parents[j] = Solution{[]Player{}, Score(100*i + j)}
+		}
+		islands[i] = &island{parents, parents[0].score, 0, 0}
+	}
+	migrate(islands, 2, 7)
+
+	// The first island gets the last island's best
+	assert.Equal(t, Score(0), islands[0].parents[0].score)
+	assert.Equal(t, Score(200), islands[0].parents[numParents-2].score)
+	assert.Equal(t, Score(201), islands[0].parents[numParents-1].score)
+	// Everybody else gets the previous island's best, which sort to the top
+	assert.Equal(t, Score(0), islands[1].parents[0].score)
+	assert.Equal(t, Score(1), islands[1].parents[1].score)
+	assert.Equal(t, Score(100), islands[2].parents[0].score)
+	// Islands that got a better solution than they'd found remember it
+	assert.Equal(t, Score(0), islands[0].topScore)
+	assert.Equal(t, 0, islands[0].topScoreRunNumber)
+	assert.Equal(t, Score(0), islands[1].topScore)
+	assert.Equal(t, 7, islands[1].topScoreRunNumber)
+}
+
+func TestRunIslands(t *testing.T) {
+	players := makeRandomPlayers(30)
+	doneSignal := make(chan os.Signal, 1)
+	doneSignal <- os.Interrupt
+	best := runIslands(newRosterIndex(players), players, islandSettings{2, 1, 1},
+		2, 1, diversitySettings{1, 2}, newHallOfFame(10), doneSignal)
+	assert.Equal(t, len(players), len(best.players))
+	score, _ := ScoreSolution(best.players)
+	assert.InDelta(t, float64(score), float64(best.score), 1e-3)
+
+	// One worker evolves the islands in turn, so the same seed finds the same
+	// solution
+	runOnce := func() Solution {
+		rand.Seed(1)
+		doneSignal <- os.Interrupt
+		return runIslands(newRosterIndex(players), players, islandSettings{2, 1, 1},
+			1, 1, diversitySettings{1, 2}, newHallOfFame(10), doneSignal)
+	}
+	assert.Equal(t, runOnce(), runOnce())
+}
@@ -0,0 +1,204 @@
+// Mutation operators, each of which makes a small random change to a solution
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// a mutationFunction makes a random change to the solution held by the engine.
+//
+// Returns false if there was no change it could make.
+type mutationFunction func(engine *scoringEngine) bool
+type mutationOperator struct {
+	name   string           // name used on the command line and in reports
+	mutate mutationFunction // how to mutate
+	weight int              // relative chance of this operator being chosen
+	// number of times the operator was used, and how many of those times it
+	// improved the score. Updated atomically, since workers share operators.
+	attempts, successes int64
+}
+
+// trackMutationStats is whether the genetic algorithm scores each mutation to
+// count how often each operator succeeds. It costs a full score per mutation,
+// so it's only on with --verbose.
+var trackMutationStats = false
+
+// Number of closest rated players to choose from when making a rating swap
+const numSimilarRatings = 3
+
+var mutationOperators = []*mutationOperator{
+	&mutationOperator{"move", movePlayer, 1, 0, 0},
+	&mutationOperator{"swap", swapPlayers, 1, 0, 0},
+	&mutationOperator{"gender-swap", swapSameGender, 1, 0, 0},
+	&mutationOperator{"rating-swap", swapSimilarRating, 1, 0, 0},
+}
+
+// movePlayer moves a random player to a random new team
+func movePlayer(engine *scoringEngine) bool {
+	engine.move(rand.Intn(len(engine.players)), uint8(rand.Intn(numTeams)))
+	return true
+}
+
+// swapWithAny swaps player i with a random player on another team who passes
+// the filter.
+//
+// Returns false if there's nobody to swap with.
+func swapWithAny(engine *scoringEngine, i int, filter PlayerFilter) bool {
+	candidates := []int{}
+	for j, player := range engine.players {
+		if player.team != engine.players[i].team && (filter == nil || filter(player)) {
+			candidates = append(candidates, j)
+		}
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+	swap(engine, i, candidates[rand.Intn(len(candidates))])
+	return true
+}
+
+func swap(engine *scoringEngine, i int, j int) {
+	teamI, teamJ := engine.players[i].team, engine.players[j].team
+	engine.move(i, teamJ)
+	engine.move(j, teamI)
+}
+
+// swapPlayers swaps two random players on different teams
+func swapPlayers(engine *scoringEngine) bool {
+	return swapWithAny(engine, rand.Intn(len(engine.players)), nil)
+}
+
+// swapSameGender swaps two random players of the same gender on different
+// teams
+func swapSameGender(engine *scoringEngine) bool {
+	i := rand.Intn(len(engine.players))
+	gender := engine.players[i].gender
+	return swapWithAny(engine, i, func(player Player) bool {
+		return player.gender == gender
+	})
+}
+
+// swapSimilarRating swaps a random player with one of the closest rated
+// players on another team
+func swapSimilarRating(engine *scoringEngine) bool {
+	i := rand.Intn(len(engine.players))
+	rating := engine.players[i].rating
+	// Keep the closest few, closest first
+	closest := make([]int, 0, numSimilarRatings+1)
+	distance := func(j int) float64 {
+		return math.Abs(float64(engine.players[j].rating - rating))
+	}
+	for j, player := range engine.players {
+		if player.team == engine.players[i].team {
+			continue
+		}
+		position := len(closest)
+		for position > 0 && distance(closest[position-1]) > distance(j) {
+			position--
+		}
+		closest = append(closest, 0)
+		copy(closest[position+1:], closest[position:])
+		closest[position] = j
+		if len(closest) > numSimilarRatings {
+			closest = closest[:numSimilarRatings]
+		}
+	}
+	if len(closest) == 0 {
+		return false
+	}
+	swap(engine, i, closest[rand.Intn(len(closest))])
+	return true
+}
+
+// chooseMutationOperator picks a random operator, based on their weights
+func chooseMutationOperator() *mutationOperator {
+	totalWeight := 0
+	for _, operator := range mutationOperators {
+		totalWeight += operator.weight
+	}
+	choice := rand.Intn(totalWeight)
+	for _, operator := range mutationOperators {
+		if choice < operator.weight {
+			return operator
+		}
+		choice -= operator.weight
+	}
+	return mutationOperators[0]
+}
+
+// applyMutationOperator mutates the solution with a random operator.
+//
+// Returns the operator, or nil if it couldn't change the solution.
+func applyMutationOperator(engine *scoringEngine) *mutationOperator {
+	operator := chooseMutationOperator()
+	if !operator.mutate(engine) {
+		return nil
+	}
+	return operator
+}
+
+// record counts a use of the operator, and whether it improved the score
+func (operator *mutationOperator) record(scoreBefore Score, scoreAfter Score) {
+	atomic.AddInt64(&operator.attempts, 1)
+	if scoreAfter < scoreBefore {
+		atomic.AddInt64(&operator.successes, 1)
+	}
+}
+
+// SetMutationWeights parses a list of operator weights, like
+// "move=1,swap=2,gender-swap=0,rating-swap=1". Operators that aren't listed
+// keep their weight.
+//
+// Returns error if an operator or weight is invalid, or all weights are 0.
+func SetMutationWeights(s string) error {
+	weights := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), "=")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid mutation weight '%s'", pair)
+		}
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil || weight < 0 {
+			return fmt.Errorf("invalid mutation weight '%s'", pair)
+		}
+		weights[parts[0]] = weight
+	}
+
+	totalWeight := 0
+	for _, operator := range mutationOperators {
+		if weight, found := weights[operator.name]; found {
+			operator.weight = weight
+			delete(weights, operator.name)
+		}
+		totalWeight += operator.weight
+	}
+	for name := range weights {
+		return fmt.Errorf("no mutation operator named '%s'", name)
+	}
+	if totalWeight == 0 {
+		return fmt.Errorf("at least one mutation operator needs a weight")
+	}
+	return nil
+}
+
+// MutationStats describes how often each operator improved the solution it
+// mutated
+func MutationStats() string {
+	stats := []string{}
+	for _, operator := range mutationOperators {
+		attempts := atomic.LoadInt64(&operator.attempts)
+		successes := atomic.LoadInt64(&operator.successes)
+		rate := 0.0
+		if attempts > 0 {
+			rate = 100 * float64(successes) / float64(attempts)
+		}
+		stats = append(stats, fmt.Sprintf("%s %.01f%% of %d", operator.name, rate, attempts))
+	}
+	return "Mutation success rates: " + strings.Join(stats, ", ")
+}
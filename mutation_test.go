@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// teamCounts counts the players on each team who pass the filter
+func teamCounts(players []Player, filter PlayerFilter) []int {
+	counts := make([]int, numTeams)
+	for _, player := range Filter(players, filter) {
+		counts[player.team] += 1
+	}
+	return counts
+}
+
+func TestSwapsKeepTeamSizes(t *testing.T) {
+	players := makeRandomPlayers(30)
+	engine := newScoringEngine(newRosterIndex(players), players)
+	playerCounts := teamCounts(players, nil)
+	for i := 0; i < 100; i++ {
+		maleCounts := teamCounts(players, IsMale)
+		swapSameGender(engine)
+		assert.Equal(t, maleCounts, teamCounts(players, IsMale))
+		assert.True(t, swapPlayers(engine))
+		assert.True(t, swapSimilarRating(engine))
+		assert.Equal(t, playerCounts, teamCounts(players, nil))
+	}
+
+	// Nobody to swap with when everyone's on the same team
+	for i := range players {
+		engine.move(i, 0)
+	}
+	assert.False(t, swapPlayers(engine))
+	assert.False(t, swapSimilarRating(engine))
+}
+
+func TestSetMutationWeights(t *testing.T) {
+	defer SetMutationWeights("move=1,swap=1,gender-swap=1,rating-swap=1")
+
+	assert.Nil(t, SetMutationWeights("move=0,swap=3"))
+	assert.Equal(t, 0, mutationOperators[0].weight)
+	assert.Equal(t, 3, mutationOperators[1].weight)
+	assert.Equal(t, 1, mutationOperators[2].weight)
+	for i := 0; i < 100; i++ {
+		assert.NotEqual(t, "move", chooseMutationOperator().name)
+	}
+
+	assert.NotNil(t, SetMutationWeights("teleport=1"))
+	assert.NotNil(t, SetMutationWeights("move=-1"))
+	assert.NotNil(t, SetMutationWeights("move"))
+	assert.NotNil(t, SetMutationWeights("move=0,swap=0,gender-swap=0,rating-swap=0"))
+}
+
+func TestMutationStatsOnlyWhenTracked(t *testing.T) {
+	defer func() { trackMutationStats = false }()
+	attempts := func() (total int64) {
+		for _, operator := range mutationOperators {
+			total += operator.attempts
+		}
+		return total
+	}
+	players := makeRandomPlayers(30)
+	engine := newScoringEngine(newRosterIndex(players), players)
+
+	before := attempts()
+	for i := 0; i < 100; i++ {
+		mutate(engine)
+	}
+	assert.Equal(t, before, attempts())
+
+	trackMutationStats = true
+	for i := 0; i < 100; i++ {
+		mutate(engine)
+	}
+	assert.True(t, attempts() > before)
+}
@@ -0,0 +1,125 @@
+// Choosing the worst case of each criterion, which its raw scores are divided
+// by before being weighted.
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxSampleStandardDeviation is the largest sample standard deviation that n
+// values between low and high can have: half of them at each end
+func maxSampleStandardDeviation(low float64, high float64, n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	numHigh := n / 2
+	variance := float64(numHigh*(n-numHigh)) / float64(n*(n-1)) * (high - low) * (high - low)
+	return math.Sqrt(variance)
+}
+
+// valueRange returns the lowest and highest of the values
+func valueRange(values []float64) (low float64, high float64) {
+	for i, value := range values {
+		if i == 0 || value < low {
+			low = value
+		}
+		if i == 0 || value > high {
+			high = value
+		}
+	}
+	return low, high
+}
+
+// ratingRange returns the lowest and highest ratings of the players
+func ratingRange(players []Player) (low float64, high float64) {
+	ratings := make([]float64, len(players))
+	for i, player := range players {
+		ratings[i] = float64(player.rating)
+	}
+	return valueRange(ratings)
+}
+
+// averageRange returns the lowest and highest average rating a team could
+// have. Empty teams average 0, so 0 is always in the range.
+func averageRange(players []Player) (low float64, high float64) {
+	low, high = ratingRange(players)
+	return math.Min(low, 0), math.Max(high, 0)
+}
+
+// AnalyticWorstCases sets the worst case of each criterion to the largest raw
+// score it could have with these players.
+//
+// Returns error if a criterion doesn't know its largest raw score.
+func AnalyticWorstCases(players []Player) error {
+	for i, criterion := range criteriaToScore {
+		if criterion.bound == nil {
+			return fmt.Errorf("criterion '%s' can't be normalized analytically",
+				criterion.name)
+		}
+		criteriaToScore[i].worstCase = criterion.bound(Filter(players, criterion.filter))
+	}
+	return nil
+}
+
+// FixedWorstCases sets the worst case of each criterion to its scale.
+//
+// Returns error if a criterion is missing a positive scale, or a scale is for
+// a criterion that doesn't exist.
+func FixedWorstCases(scales map[string]Score) error {
+	known := make(map[string]bool)
+	for i, criterion := range criteriaToScore {
+		known[criterion.name] = true
+		scale, found := scales[criterion.name]
+		if !found {
+			return fmt.Errorf("no scale for criterion '%s'", criterion.name)
+		}
+		if scale <= 0 {
+			return fmt.Errorf("scale for criterion '%s' must be positive", criterion.name)
+		}
+		criteriaToScore[i].worstCase = scale
+	}
+	for name := range scales {
+		if !known[name] {
+			return fmt.Errorf("scale for unknown criterion '%s'", name)
+		}
+	}
+	return nil
+}
+
+// Normalize sets the worst case of each criterion using the given strategy:
+// "sample" (the worst of numSamples random solutions), "analytic" (the worst
+// possible) or "fixed" (from scales). Reports how we normalized.
+func Normalize(strategy string, players []Player, numSamples int,
+	scales map[string]Score) error {
+	for i := range criteriaToScore {
+		criteriaToScore[i].worstCase = 0
+	}
+	switch strategy {
+	case "sample":
+		numNaN := PopulateWorstCases(randomSolutions(players, numSamples))
+		fmt.Printf("Normalized by the worst scores of %d random solutions\n", numSamples)
+		for i, criterion := range criteriaToScore {
+			if numNaN[i] > 0 {
+				fmt.Printf("Skipped %d NaN raw scores for %s\n", numNaN[i], criterion.name)
+			}
+		}
+	case "analytic":
+		if err := AnalyticWorstCases(players); err != nil {
+			return err
+		}
+		fmt.Println("Normalized by the worst possible score of each criterion")
+	case "fixed":
+		if err := FixedWorstCases(scales); err != nil {
+			return err
+		}
+		fmt.Println("Normalized by the scales file")
+	default:
+		return fmt.Errorf("unknown normalization '%s'", strategy)
+	}
+	for _, criterion := range criteriaToScore {
+		newLog.Debug("Worst case of %s: %.02f", criterion.name, criterion.worstCase)
+	}
+	return nil
+}
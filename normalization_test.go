@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/GaryBoone/GoStats/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+// saveWorstCases returns a function that puts the worst cases back
+func saveWorstCases() func() {
+	saved := append([]criterion{}, criteriaToScore...)
+	return func() { criteriaToScore = saved }
+}
+
+func TestMaxSampleStandardDeviation(t *testing.T) {
+	assert.InDelta(t, stats.StatsSampleStandardDeviation([]float64{2, 2, 2, 5, 5, 5}),
+		maxSampleStandardDeviation(2, 5, 6), 1e-9)
+	assert.InDelta(t, stats.StatsSampleStandardDeviation([]float64{2, 2, 5, 5, 5}),
+		maxSampleStandardDeviation(2, 5, 5), 1e-9)
+	assert.Equal(t, 0.0, maxSampleStandardDeviation(2, 5, 1))
+}
+
+func TestAnalyticWorstCases(t *testing.T) {
+	defer saveWorstCases()()
+	players := makeBaggagePlayers()
+	assert.Nil(t, AnalyticWorstCases(players))
+	numBaggages := 0
+	for _, player := range players {
+		numBaggages += len(player.baggages)
+	}
+	assert.Equal(t, Score(numBaggages), criteriaToScore[0].worstCase)
+	assert.Equal(t, Score(len(players)-1), criteriaToScore[1].worstCase)
+
+	// No solution's team averages can be more spread out than the bound
+	players = makeRandomPlayers(60)
+	assert.Nil(t, AnalyticWorstCases(players))
+	for _, solution := range randomSolutions(players, 10) {
+		_, rawScores := ScoreSolution(solution.players)
+		assert.True(t, rawScores[4] <= criteriaToScore[4].worstCase)
+	}
+}
+
+// makeOneTeamRoster puts players rated from 50 to 100 all on one team, leaving
+// the rest empty
+func makeOneTeamRoster() []Player {
+	players := make([]Player, 11)
+	for i := range players {
+		players[i] = Player{name: Name{string(rune('A' + i)), "Player"},
+			rating: float32(50 + 5*i), gender: Male, team: 0}
+	}
+	return players
+}
+
+func TestAnalyticWorstCasesWithEmptyTeams(t *testing.T) {
+	defer saveWorstCases()()
+	players := makeOneTeamRoster()
+	assert.Nil(t, AnalyticWorstCases(players))
+	_, rawScores := ScoreSolution(players)
+	for i, criterion := range criteriaToScore {
+		if criterion.name == "average rating players" {
+			assert.InDelta(t, 30.6, float64(rawScores[i]), 0.1)
+		}
+		assert.True(t, rawScores[i] <= criterion.worstCase, criterion.name)
+	}
+
+	teams := splitIntoTeams(players)
+	for _, kind := range []string{"median", "spread"} {
+		c := mustNewCriterion(kind, kind, nil, 0, 1)
+		assert.True(t, c.rawScore(teams) <= c.bound(players), kind)
+	}
+}
+
+func TestFixedWorstCases(t *testing.T) {
+	defer saveWorstCases()()
+	scales := make(map[string]Score)
+	for _, criterion := range criteriaToScore {
+		scales[criterion.name] = 2
+	}
+	assert.Nil(t, FixedWorstCases(scales))
+	assert.Equal(t, Score(2), criteriaToScore[3].worstCase)
+
+	scales["no such criterion"] = 1
+	assert.NotNil(t, FixedWorstCases(scales))
+	delete(scales, "no such criterion")
+	scales["number of males"] = 0
+	assert.NotNil(t, FixedWorstCases(scales))
+	delete(scales, "number of males")
+	assert.NotNil(t, FixedWorstCases(scales))
+}
@@ -0,0 +1,341 @@
+// Multi-objective search. Rather than adding every criterion into one score, we
+// keep the rosters that make the best trade-offs between a few chosen criteria,
+// using NSGA-II style selection, and let the commissioners choose between them.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	paretoPopulationSize = 100
+	// most trade-off rosters we remember at once
+	paretoArchiveSize = 200
+)
+
+type paretoSettings struct {
+	criteria       []int // indices into criteriaToScore of the criteria to trade off
+	numGenerations int
+	numRosters     int // number of trade-off rosters to show
+}
+
+// paretoSolution is a solution along with its score on each objective. The
+// objectives are the weighted score of each chosen criterion, followed by the
+// total of all the other criteria.
+type paretoSolution struct {
+	solution   Solution
+	objectives []Score
+	rank       int     // which front the solution is on, 0 being the best
+	crowding   float64 // how spread out the solution's neighbors on its front are
+}
+
+// ParseParetoCriteria finds the criteria named in a comma separated list.
+//
+// Returns error if a criterion doesn't exist or is listed twice.
+func ParseParetoCriteria(s string) ([]int, error) {
+	criteria := []int{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		found := -1
+		for i, criterion := range criteriaToScore {
+			if strings.EqualFold(criterion.name, name) {
+				found = i
+			}
+		}
+		if found == -1 {
+			return nil, fmt.Errorf("no criterion named '%s'", name)
+		}
+		for _, c := range criteria {
+			if c == found {
+				return nil, fmt.Errorf("criterion '%s' is listed twice", name)
+			}
+		}
+		criteria = append(criteria, found)
+	}
+	return criteria, nil
+}
+
+// scoreObjectives scores the engine's solution on each objective
+func scoreObjectives(engine *scoringEngine, criteria []int) paretoSolution {
+	weightedScores := make([]Score, len(criteriaToScore))
+	totalScore := engine.scoreCriteria(weightedScores)
+	objectives := make([]Score, len(criteria)+1)
+	for i, c := range criteria {
+		objectives[i] = weightedScores[c]
+		weightedScores[c] = 0
+	}
+	for _, weightedScore := range weightedScores {
+		objectives[len(criteria)] += weightedScore
+	}
+	return paretoSolution{solution: Solution{engine.players, totalScore}, objectives: objectives}
+}
+
+// dominates returns true if a is at least as good as b on every objective, and
+// better on at least one
+func dominates(a []Score, b []Score) bool {
+	better := false
+	for i := range a {
+		if a[i] > b[i] {
+			return false
+		}
+		if a[i] < b[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// sortFronts splits the population into fronts. Nobody on a front is dominated
+// by anyone on it or a later front. Sets the rank of each solution.
+//
+// Returns the indices of the solutions on each front, best front first.
+func sortFronts(population []paretoSolution) [][]int {
+	dominatedBy := make([]int, len(population)) // number of solutions dominating each
+	dominating := make([][]int, len(population))
+	front := []int{}
+	for i := range population {
+		for j := range population {
+			if dominates(population[i].objectives, population[j].objectives) {
+				dominating[i] = append(dominating[i], j)
+			} else if dominates(population[j].objectives, population[i].objectives) {
+				dominatedBy[i] += 1
+			}
+		}
+		if dominatedBy[i] == 0 {
+			front = append(front, i)
+		}
+	}
+
+	fronts := [][]int{}
+	for rank := 0; len(front) > 0; rank++ {
+		fronts = append(fronts, front)
+		nextFront := []int{}
+		for _, i := range front {
+			population[i].rank = rank
+			for _, j := range dominating[i] {
+				dominatedBy[j] -= 1
+				if dominatedBy[j] == 0 {
+					nextFront = append(nextFront, j)
+				}
+			}
+		}
+		front = nextFront
+	}
+	return fronts
+}
+
+// assignCrowding sets the crowding distance of each solution on the front: the
+// size of the gap around it on each objective, relative to the range of that
+// objective. Solutions at either end of an objective's range are kept first.
+func assignCrowding(population []paretoSolution, front []int) {
+	for _, i := range front {
+		population[i].crowding = 0
+	}
+	sorted := append([]int{}, front...)
+	for objective := range population[front[0]].objectives {
+		value := func(k int) float64 {
+			return float64(population[sorted[k]].objectives[objective])
+		}
+		sort.SliceStable(sorted, func(a, b int) bool { return value(a) < value(b) })
+		low, high := value(0), value(len(sorted)-1)
+		population[sorted[0]].crowding = math.Inf(1)
+		population[sorted[len(sorted)-1]].crowding = math.Inf(1)
+		if high == low {
+			continue
+		}
+		for k := 1; k < len(sorted)-1; k++ {
+			population[sorted[k]].crowding += (value(k+1) - value(k-1)) / (high - low)
+		}
+	}
+}
+
+// removeDuplicates drops any solution that is the same as an earlier one but
+// for its team labels
+func removeDuplicates(population []paretoSolution) []paretoSolution {
+	unique := []paretoSolution{}
+	seen := make(map[string]bool)
+	for _, candidate := range population {
+		description := canonicalTeams(candidate.solution.players)
+		if !seen[description] {
+			seen[description] = true
+			unique = append(unique, candidate)
+		}
+	}
+	return unique
+}
+
+// selectSurvivors keeps the best size solutions, filling front by front. When a
+// front doesn't fit, its least crowded solutions are kept.
+func selectSurvivors(population []paretoSolution, size int) []paretoSolution {
+	population = removeDuplicates(population)
+	survivors := make([]paretoSolution, 0, size)
+	for _, front := range sortFronts(population) {
+		if len(survivors) == size {
+			break
+		}
+		assignCrowding(population, front)
+		if len(survivors)+len(front) > size {
+			sort.SliceStable(front, func(a, b int) bool {
+				return population[front[a]].crowding > population[front[b]].crowding
+			})
+			front = front[:size-len(survivors)]
+		}
+		for _, i := range front {
+			survivors = append(survivors, population[i])
+		}
+	}
+	return survivors
+}
+
+// updateArchive adds the candidates to the archive of trade-off rosters,
+// keeping only those that no other roster dominates.
+func updateArchive(archive []paretoSolution, candidates []paretoSolution) []paretoSolution {
+	archive = removeDuplicates(append(archive, candidates...))
+	front := sortFronts(archive)[0]
+	best := make([]paretoSolution, len(front))
+	for k, i := range front {
+		best[k] = archive[i]
+	}
+	return selectSurvivors(best, paretoArchiveSize)
+}
+
+func sameObjectives(a []Score, b []Score) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// crowdedTournament picks the better of two random solutions: the one on the
+// better front, or if they're on the same front, the less crowded one
+func crowdedTournament(population []paretoSolution) paretoSolution {
+	a := population[rand.Intn(len(population))]
+	b := population[rand.Intn(len(population))]
+	if b.rank < a.rank || (b.rank == a.rank && b.crowding > a.crowding) {
+		return b
+	}
+	return a
+}
+
+// chooseTradeOffs picks up to numRosters rosters from the archive: the one with
+// the best total score, then the ones most spread out from the others. Rosters
+// that score the same as one already picked are skipped.
+//
+// Returns the rosters sorted by total score.
+func chooseTradeOffs(archive []paretoSolution, numRosters int) []paretoSolution {
+	archive = append([]paretoSolution{}, archive...)
+	sort.SliceStable(archive, func(a, b int) bool {
+		return archive[a].solution.score < archive[b].solution.score
+	})
+	if len(archive) <= numRosters {
+		return archive
+	}
+	if numRosters < 1 {
+		numRosters = 1
+	}
+	others := make([]int, len(archive)-1)
+	for k := range others {
+		others[k] = k + 1
+	}
+	assignCrowding(archive, others)
+	sort.SliceStable(others, func(a, b int) bool {
+		return archive[others[a]].crowding > archive[others[b]].crowding
+	})
+	chosen := []paretoSolution{archive[0]}
+	for _, i := range others {
+		if len(chosen) == numRosters {
+			break
+		}
+		repeated := false
+		for _, roster := range chosen {
+			if sameObjectives(roster.objectives, archive[i].objectives) {
+				repeated = true
+			}
+		}
+		if !repeated {
+			chosen = append(chosen, archive[i])
+		}
+	}
+	sort.SliceStable(chosen, func(a, b int) bool {
+		return chosen[a].solution.score < chosen[b].solution.score
+	})
+	return chosen
+}
+
+// SearchPareto evolves a population of random solutions for the given number
+// of generations, or until we're told to stop.
+//
+// Returns a few rosters that trade the chosen criteria off against each other,
+// none of which is beaten on every criterion by any roster we found.
+func SearchPareto(index *rosterIndex, players []Player, settings paretoSettings,
+	doneSignal <-chan os.Signal) []paretoSolution {
+	population := make([]paretoSolution, paretoPopulationSize)
+	for i := range population {
+		ourPlayers := make([]Player, len(players))
+		copy(ourPlayers, players)
+		randomizeTeams(ourPlayers)
+		population[i] = scoreObjectives(newScoringEngine(index, ourPlayers), settings.criteria)
+	}
+	population = selectSurvivors(population, paretoPopulationSize)
+	archive := updateArchive(nil, population)
+
+searching:
+	for generation := 0; generation < settings.numGenerations; generation++ {
+		select {
+		case <-doneSignal:
+			fmt.Println("Exit signal received")
+			break searching
+		default:
+		}
+
+		children := make([]paretoSolution, paretoPopulationSize)
+		for i := range children {
+			newPlayers := crossover(
+				crowdedTournament(population).solution, crowdedTournament(population).solution)
+			engine := newScoringEngine(index, newPlayers)
+			mutate(engine)
+			children[i] = scoreObjectives(engine, settings.criteria)
+		}
+		population = selectSurvivors(append(population, children...), paretoPopulationSize)
+		archive = updateArchive(archive, population)
+		newLog.Debug("Generation %d. %d trade-off rosters found", generation, len(archive))
+	}
+
+	fmt.Printf("Found %d trade-off rosters\n", len(archive))
+	return chooseTradeOffs(archive, settings.numRosters)
+}
+
+// PrintParetoFront shows how each roster scores on each objective, then the
+// rosters themselves
+func PrintParetoFront(rosters []paretoSolution, criteria []int) {
+	writer := new(tabwriter.Writer)
+	writer.Init(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(writer, "Roster\t")
+	for _, c := range criteria {
+		fmt.Fprintf(writer, "%s\t", criteriaToScore[c].name)
+	}
+	fmt.Fprint(writer, "everything else\ttotal\t\n")
+	for i, roster := range rosters {
+		fmt.Fprintf(writer, "%d\t", i+1)
+		for _, objective := range roster.objectives {
+			fmt.Fprintf(writer, "%.02f\t", objective)
+		}
+		fmt.Fprintf(writer, "%.02f\t\n", roster.solution.score)
+	}
+	writer.Flush()
+
+	for i, roster := range rosters {
+		fmt.Printf("\nTrade-off roster %d of %d\n", i+1, len(rosters))
+		PrintTeams(roster.solution)
+		PrintSolutionScoring(roster.solution)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParetoCriteria(t *testing.T) {
+	criteria, err := ParseParetoCriteria("matching baggages, Number of Males")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 2}, criteria)
+
+	_, err = ParseParetoCriteria("matching baggages,no such criterion")
+	assert.NotNil(t, err)
+	_, err = ParseParetoCriteria("number of males,number of males")
+	assert.NotNil(t, err)
+}
+
+func TestSortFronts(t *testing.T) {
+	population := []paretoSolution{
+		{objectives: []Score{1, 4}},
+		{objectives: []Score{2, 2}},
+		{objectives: []Score{2, 3}}, // dominated by the second
+		{objectives: []Score{4, 1}},
+		{objectives: []Score{5, 5}}, // dominated by everybody
+	}
+	fronts := sortFronts(population)
+	assert.Equal(t, [][]int{{0, 1, 3}, {2}, {4}}, fronts)
+	assert.Equal(t, 1, population[2].rank)
+
+	// The ends of the front are kept first
+	assignCrowding(population, fronts[0])
+	assert.True(t, math.IsInf(population[0].crowding, 1))
+	assert.True(t, math.IsInf(population[3].crowding, 1))
+	assert.InDelta(t, 2.0, population[1].crowding, 1e-9)
+}
+
+func TestSearchPareto(t *testing.T) {
+	players := makeRandomPlayers(30)
+	criteria := []int{0, 2}
+	rosters := SearchPareto(newRosterIndex(players), players,
+		paretoSettings{criteria, 5, 3}, make(chan os.Signal, 1))
+	assert.True(t, len(rosters) > 0)
+	assert.True(t, len(rosters) <= 3)
+	for _, roster := range rosters {
+		score, _ := ScoreSolution(roster.solution.players)
+		assert.InDelta(t, float64(score), float64(roster.solution.score), 1e-3)
+		total := Score(0)
+		for _, objective := range roster.objectives {
+			total += objective
+		}
+		assert.InDelta(t, float64(total), float64(roster.solution.score), 1e-3)
+		for _, other := range rosters {
+			assert.False(t, dominates(other.objectives, roster.objectives))
+		}
+	}
+}
@@ -35,12 +35,24 @@ func IsFemale(player Player) bool {
 type Name struct {
 	firstName, lastName string
 }
+
+// Implement fmt.Stringer for printing names
+func (name Name) String() string {
+	return fmt.Sprintf("%s %s", name.firstName, name.lastName)
+}
+
 type Player struct {
 	name     Name
 	rating   float32
 	gender   Gender
 	team     uint8
 	baggages []Name
+	// every column from the players file, for scripted criteria
+	attributes map[string]string
+	// roles from the "Role" column, and lower case positions from the
+	// "Positions" column, parsed once at load
+	roles     []string
+	positions []string
 }
 
 // FindPlayer returns the first matching player in the list of players.
@@ -78,6 +90,20 @@ func (a ByRating) Less(i, j int) bool {
 
 type PlayerFilter func(player Player) bool
 
+// StringToFilter parses the name of a filter: "Male", "Female", or "" (or
+// "All") for no filter.
+func StringToFilter(s string) (PlayerFilter, error) {
+	switch s {
+	case "", "All":
+		return nil, nil
+	case "Male":
+		return IsMale, nil
+	case "Female":
+		return IsFemale, nil
+	}
+	return nil, fmt.Errorf("invalid filter '%s'", s)
+}
+
 func Filter(players []Player, filter PlayerFilter) (filteredPlayers []Player) {
 	for _, player := range players {
 		if filter == nil || filter(player) {
@@ -20,3 +20,15 @@ func TestStringToGender(t *testing.T) {
 	gender, err = StringToGender("asdf")
 	assert.NotNil(t, err)
 }
+
+func TestStringToFilter(t *testing.T) {
+	filter, err := StringToFilter("Male")
+	assert.Nil(t, err)
+	assert.True(t, filter(Player{gender: Male}))
+	assert.False(t, filter(Player{gender: Female}))
+	filter, err = StringToFilter("")
+	assert.Nil(t, err)
+	assert.Nil(t, filter)
+	filter, err = StringToFilter("asdf")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,85 @@
+// Local search to polish off the genetic algorithm's best solution
+
+package main
+
+// tryMove moves the player at index i to team, keeping the move if it improves
+// on bestScore.
+//
+// Returns the new best score, and whether or not the move was kept.
+func tryMove(engine *scoringEngine, i int, team uint8, bestScore Score) (Score, bool) {
+	originalTeam := engine.players[i].team
+	engine.move(i, team)
+	score := engine.score()
+	if score < bestScore {
+		return score, true
+	}
+	engine.move(i, originalTeam)
+	return bestScore, false
+}
+
+// trySwap swaps the teams of the players at indices i and j, keeping the swap
+// if it improves on bestScore.
+//
+// Returns the new best score, and whether or not the swap was kept.
+func trySwap(engine *scoringEngine, i int, j int, bestScore Score) (Score, bool) {
+	teamI, teamJ := engine.players[i].team, engine.players[j].team
+	engine.move(i, teamJ)
+	engine.move(j, teamI)
+	score := engine.score()
+	if score < bestScore {
+		return score, true
+	}
+	engine.move(i, teamI)
+	engine.move(j, teamJ)
+	return bestScore, false
+}
+
+// Polish hill-climbs from the given solution until it reaches a local optimum.
+//
+// Each pass tries moving every player to every other team, then swapping every
+// pair of players on different teams, keeping any change that improves the
+// score. We stop after a pass that finds no improvements. The input solution is
+// not modified.
+func Polish(solution Solution) Solution {
+	return polishPinned(solution, nil)
+}
+
+// polishPinned is Polish, leaving the players marked as pinned on their teams.
+// If pinned is nil, no players are pinned.
+func polishPinned(solution Solution, pinned []bool) Solution {
+	isPinned := func(i int) bool {
+		return pinned != nil && pinned[i]
+	}
+	players := make([]Player, len(solution.players))
+	copy(players, solution.players)
+	engine := newScoringEngine(newRosterIndex(players), players)
+	bestScore := engine.score()
+
+	for improved := true; improved; {
+		improved = false
+		for i := range players {
+			if isPinned(i) {
+				continue
+			}
+			for team := 0; team < numTeams; team++ {
+				if uint8(team) == players[i].team {
+					continue
+				}
+				var kept bool
+				bestScore, kept = tryMove(engine, i, uint8(team), bestScore)
+				improved = improved || kept
+			}
+		}
+		for i := range players {
+			for j := i + 1; j < len(players); j++ {
+				if players[i].team == players[j].team || isPinned(i) || isPinned(j) {
+					continue
+				}
+				var kept bool
+				bestScore, kept = trySwap(engine, i, j, bestScore)
+				improved = improved || kept
+			}
+		}
+	}
+	return Solution{players, bestScore}
+}
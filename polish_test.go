@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolish(t *testing.T) {
+	players := make([]Player, numTeams*3)
+	for i := range players {
+		gender := Male
+		if i%2 == 0 {
+			gender = Female
+		}
+		players[i] = Player{name: Name{string(rune('A' + i)), "Player"},
+			rating: float32(10 * i), gender: gender, baggages: []Name{}}
+	}
+	// Start with everyone on one team
+	score, _ := ScoreSolution(players)
+	solution := Solution{players, score}
+
+	polished := Polish(solution)
+	assert.True(t, polished.score < solution.score)
+	polishedScore, _ := ScoreSolution(polished.players)
+	assert.InDelta(t, float64(polishedScore), float64(polished.score), 1e-3)
+	for _, player := range solution.players {
+		assert.Equal(t, uint8(0), player.team)
+	}
+
+	// We stopped at a local optimum, so no single move can help
+	engine := newScoringEngine(newRosterIndex(polished.players), polished.players)
+	for i := range polished.players {
+		for team := 0; team < numTeams; team++ {
+			_, kept := tryMove(engine, i, uint8(team), polished.score)
+			assert.False(t, kept)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Positions, for sports where each team needs a set composition, like 2
+// goalies and 6 defenders. Players list the positions they can play in a
+// "Positions" column of the players file, and each player fills one position.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Weight of the criterion for filling each team's positions. It matches the
+// weight of spreading the males and females.
+const positionWeight = 1200
+
+// Weight of balancing the ratings of the players at each position. It matches
+// the weight of the top males' average rating.
+const positionRatingWeight = 5
+
+// parsePositions reads the positions in a player's "Positions" column, in lower
+// case. A player who can play several lists them separated by ";", like
+// "goalie;defender".
+func parsePositions(column string) []string {
+	positions := parseList(column)
+	for i, position := range positions {
+		positions[i] = strings.ToLower(position)
+	}
+	return positions
+}
+
+// playsPosition makes a filter for the players who can play the position
+func playsPosition(position string) PlayerFilter {
+	position = strings.ToLower(position)
+	return func(player Player) bool {
+		for _, playerPosition := range player.positions {
+			if playerPosition == position {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// positionQuotas are the players needed in each position. The positions are
+// numbered, so matching players to them doesn't need maps.
+type positionQuotas struct {
+	numbers  map[string]int // number of each lower case position
+	counts   []int          // players needed in each position, by number
+	numSlots int            // players needed in all
+}
+
+func newPositionQuotas(quotas map[string]int) positionQuotas {
+	q := positionQuotas{numbers: make(map[string]int)}
+	for position, count := range quotas {
+		q.numbers[position] = len(q.counts)
+		q.counts = append(q.counts, count)
+		q.numSlots += count
+	}
+	return q
+}
+
+// times returns the quotas for the given number of teams
+func (q positionQuotas) times(teams int) positionQuotas {
+	scaled := positionQuotas{q.numbers, make([]int, len(q.counts)), q.numSlots * teams}
+	for number, count := range q.counts {
+		scaled.counts[number] = count * teams
+	}
+	return scaled
+}
+
+// matchPositions finds the most positions that can be filled by the players.
+// Each player fills at most one position. It's a bipartite matching between
+// players and positions, found with augmenting paths.
+func matchPositions(players []Player, quotas positionQuotas) int {
+	eligible := make([][]int, len(players))
+	for i, player := range players {
+		for _, position := range player.positions {
+			if number, found := quotas.numbers[position]; found && quotas.counts[number] > 0 {
+				eligible[i] = append(eligible[i], number)
+			}
+		}
+	}
+	// filledBy[position] are the players filling the position
+	filledBy := make([][]int, len(quotas.counts))
+	visited := make([]bool, len(quotas.counts))
+	var augment func(player int) bool
+	augment = func(player int) bool {
+		for _, position := range eligible[player] {
+			if visited[position] {
+				continue
+			}
+			visited[position] = true
+			if len(filledBy[position]) < quotas.counts[position] {
+				filledBy[position] = append(filledBy[position], player)
+				return true
+			}
+			// Try to move one of the players filling the position elsewhere
+			for k, other := range filledBy[position] {
+				if augment(other) {
+					filledBy[position][k] = player
+					return true
+				}
+			}
+		}
+		return false
+	}
+	numFilled := 0
+	for i := range players {
+		if numFilled == quotas.numSlots {
+			break
+		}
+		for position := range visited {
+			visited[position] = false
+		}
+		if augment(i) {
+			numFilled += 1
+		}
+	}
+	return numFilled
+}
+
+// positionCriterion scores how far each team is from filling its quota of
+// players in each position
+type positionCriterion struct {
+	name   string
+	quotas positionQuotas // players needed per team
+}
+
+// newPositionCriterion reads the quotas from the file named in the argument.
+//
+// Returns error if the file is invalid or has no quotas.
+func newPositionCriterion(name string, argument string) (Criterion, error) {
+	if argument == "" {
+		return nil, fmt.Errorf("criterion '%s' needs a file of position quotas", name)
+	}
+	quotas, err := ParsePositionQuotas(argument)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotas) == 0 {
+		return nil, fmt.Errorf("criterion '%s' has no position quotas", name)
+	}
+	return positionCriterion{name, newPositionQuotas(quotas)}, nil
+}
+
+func (c positionCriterion) Name() string {
+	return c.name
+}
+
+// Score is the total number of positions left unfilled on the teams
+func (c positionCriterion) Score(teams []Team) Score {
+	score, _ := c.scoreAndValues(teams)
+	return score
+}
+
+// scoreAndValues is the total left unfilled, and the number on each team
+func (c positionCriterion) scoreAndValues(teams []Team) (Score, []float64) {
+	unfilled := c.RawValues(teams)
+	score := 0.0
+	for _, value := range unfilled {
+		score += value
+	}
+	return Score(score), unfilled
+}
+
+// RawValues is the number of positions left unfilled on each team
+func (c positionCriterion) RawValues(teams []Team) []float64 {
+	unfilled := make([]float64, len(teams))
+	for i, team := range teams {
+		unfilled[i] = float64(c.quotas.numSlots - matchPositions(team.players, c.quotas))
+	}
+	return unfilled
+}
+
+// boundFunction is the number left unfilled when everybody is on one team
+func (c positionCriterion) boundFunction() criterionBoundFunction {
+	return func(players []Player) Score {
+		teams := make([]Team, numTeams)
+		teams[0].players = players
+		return c.Score(teams)
+	}
+}
+
+// PositionCriteria makes the criteria for the quotas in the file: one for
+// filling each team's positions, and one for each position balancing the
+// average rating of the best players who can play it, as many as the quota.
+//
+// Returns error if the file is invalid.
+func PositionCriteria(quotasFilename string) ([]criterion, error) {
+	quotasCriterion, err := newCriterion(
+		"unfilled positions", "positions", quotasFilename, nil, 0, positionWeight)
+	if err != nil {
+		return nil, err
+	}
+	criteria := []criterion{quotasCriterion}
+	quotas := quotasCriterion.scorer.(positionCriterion).quotas
+	positions := []string{}
+	for position := range quotas.numbers {
+		positions = append(positions, position)
+	}
+	sort.Strings(positions)
+	for _, position := range positions {
+		quota := quotas.counts[quotas.numbers[position]]
+		if quota == 0 {
+			continue
+		}
+		criteria = append(criteria, mustNewCriterion(
+			"average rating top "+position, "average", playsPosition(position),
+			quota, positionRatingWeight))
+	}
+	return criteria, nil
+}
+
+// CheckPositionFeasibility checks that the players can fill every team's
+// positions, if any criterion has position quotas, and warns if they can't.
+//
+// Returns error if the players file has no "Positions" column.
+func CheckPositionFeasibility(players []Player) error {
+	for _, criterion := range criteriaToScore {
+		positions, ok := criterion.scorer.(positionCriterion)
+		if !ok || len(players) == 0 {
+			continue
+		}
+		if _, found := players[0].attributes["Positions"]; !found {
+			return fmt.Errorf("criterion '%s' needs a \"Positions\" column in the "+
+				"players file", criterion.name)
+		}
+		leagueQuotas := positions.quotas.times(numTeams)
+		numSlots := leagueQuotas.numSlots
+		if numFilled := matchPositions(players, leagueQuotas); numFilled < numSlots {
+			newLog.Warning("The players can only fill %d of the %d positions on "+
+				"the teams, so some will be left unfilled", numFilled, numSlots)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makePositionPlayers(positions ...string) []Player {
+	players := make([]Player, len(positions))
+	for i, position := range positions {
+		players[i] = Player{rating: float32(i),
+			attributes: map[string]string{"Positions": position},
+			positions:  parsePositions(position)}
+	}
+	return players
+}
+
+func TestMatchPositions(t *testing.T) {
+	quotas := newPositionQuotas(map[string]int{"goalie": 1, "defender": 2})
+	// The goalie who can also defend has to be moved to make room
+	players := makePositionPlayers("Goalie;Defender", "goalie", "defender")
+	assert.Equal(t, 3, matchPositions(players, quotas))
+	players = makePositionPlayers("goalie", "goalie", "defender", "")
+	assert.Equal(t, 2, matchPositions(players, quotas))
+	assert.Equal(t, 0, matchPositions(nil, quotas))
+	assert.Equal(t, 6, matchPositions(
+		makePositionPlayers("goalie", "goalie", "defender", "defender", "defender",
+			"defender", "goalie"), quotas.times(2)))
+	assert.Equal(t, []string{"goalie", "defender"}, parsePositions(" Goalie; ;DEFENDER"))
+}
+
+func TestPositionCriteria(t *testing.T) {
+	defer saveWorstCases()()
+	filename := filepath.Join(t.TempDir(), "quotas.csv")
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"Position,Count\nGoalie,1\nDefender,2\nWinger,0\n"), 0644))
+	criteria, err := PositionCriteria(filename)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(criteria))
+	assert.Equal(t, "average rating top defender", criteria[1].name)
+	assert.Equal(t, 2, criteria[1].numPlayers)
+
+	teams := make([]Team, numTeams)
+	for i := range teams {
+		teams[i].players = makePositionPlayers("goalie", "defender", "defender")
+	}
+	assert.Equal(t, Score(0), criteria[0].rawScore(teams))
+	teams[0].players[0].positions = []string{"defender"}
+	assert.Equal(t, Score(1), criteria[0].rawScore(teams))
+	assert.Equal(t, float64(1), criteria[0].scorer.RawValues(teams)[0])
+
+	assert.Nil(t, SetCriteria(append(criteriaToScore, criteria...)))
+	assert.Nil(t, CheckPositionFeasibility(makePositionPlayers("goalie")))
+	assert.NotNil(t, CheckPositionFeasibility([]Player{{}}))
+
+	assert.Nil(t, os.WriteFile(filename, []byte("Position,Count\nGoalie,x\n"), 0644))
+	_, err = PositionCriteria(filename)
+	assert.NotNil(t, err)
+}
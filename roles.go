@@ -0,0 +1,140 @@
+// Roles like captain, coach, handler or goalie, from the "Role" column of the
+// players file, with limits on how many of each role a team should have.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Weight of each criterion read from --role-constraints. It matches the weight
+// of spreading the males and females.
+const roleWeight = 1200
+
+// parseList reads a column of the players file that lists several values,
+// separated by ";", like "captain;handler". Blanks are left out.
+func parseList(column string) []string {
+	values := []string{}
+	for _, value := range strings.Split(column, ";") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// hasRole is whether the role is one of the player's, ignoring case
+func (player Player) hasRole(role string) bool {
+	for _, playerRole := range player.roles {
+		if strings.EqualFold(playerRole, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// roleCriterion scores how far each team is from having between min and max
+// players with the role
+type roleCriterion struct {
+	name     string
+	role     string
+	min, max int // max is -1 if there's no maximum
+}
+
+// newRoleCriterion parses an argument of "role:min:max". A blank min is 0, and a
+// blank max is no maximum.
+//
+// Returns error if the argument isn't in that form.
+func newRoleCriterion(name string, argument string) (Criterion, error) {
+	fields := strings.Split(argument, ":")
+	if len(fields) != 3 || fields[0] == "" {
+		return nil, fmt.Errorf("criterion '%s' needs an argument of role:min:max, "+
+			"not '%s'", name, argument)
+	}
+	c := roleCriterion{name: name, role: fields[0], min: 0, max: -1}
+	var err error
+	if fields[1] != "" {
+		if c.min, err = strconv.Atoi(fields[1]); err != nil || c.min < 0 {
+			return nil, fmt.Errorf("invalid minimum '%s' for role '%s'", fields[1], c.role)
+		}
+	}
+	if fields[2] != "" {
+		if c.max, err = strconv.Atoi(fields[2]); err != nil || c.max < c.min {
+			return nil, fmt.Errorf("invalid maximum '%s' for role '%s'", fields[2], c.role)
+		}
+	}
+	return c, nil
+}
+
+func (c roleCriterion) Name() string {
+	return c.name
+}
+
+// violation is how many players the team is short of the minimum or over the
+// maximum
+func (c roleCriterion) violation(count int) int {
+	if count < c.min {
+		return c.min - count
+	}
+	if c.max >= 0 && count > c.max {
+		return count - c.max
+	}
+	return 0
+}
+
+// Score is the total violation over the teams
+func (c roleCriterion) Score(teams []Team) Score {
+	score, _ := c.scoreAndValues(teams)
+	return score
+}
+
+// scoreAndValues is the total violation, and the count on each team
+func (c roleCriterion) scoreAndValues(teams []Team) (Score, []float64) {
+	counts := c.RawValues(teams)
+	score := 0
+	for _, count := range counts {
+		score += c.violation(int(count))
+	}
+	return Score(score), counts
+}
+
+// RawValues is the number of players with the role on each team
+func (c roleCriterion) RawValues(teams []Team) []float64 {
+	counts := make([]float64, len(teams))
+	for i, team := range teams {
+		for _, player := range team.players {
+			if player.hasRole(c.role) {
+				counts[i] += 1
+			}
+		}
+	}
+	return counts
+}
+
+// boundFunction is the violation when everybody is on one team
+func (c roleCriterion) boundFunction() criterionBoundFunction {
+	return func(players []Player) Score {
+		teams := make([]Team, numTeams)
+		teams[0].players = players
+		return c.Score(teams)
+	}
+}
+
+// ValidateRoles checks that the players file has a "Role" column if any
+// criterion needs one
+//
+// Returns error naming the first criterion that needs it.
+func ValidateRoles(players []Player) error {
+	for _, criterion := range criteriaToScore {
+		if _, ok := criterion.scorer.(roleCriterion); !ok || len(players) == 0 {
+			continue
+		}
+		if _, found := players[0].attributes["Role"]; !found {
+			return fmt.Errorf("criterion '%s' needs a \"Role\" column in the "+
+				"players file", criterion.name)
+		}
+	}
+	return nil
+}
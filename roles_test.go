@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeRolePlayer(role string) Player {
+	return Player{attributes: map[string]string{"Role": role}, roles: parseList(role)}
+}
+
+func TestRoles(t *testing.T) {
+	assert.Equal(t, []string{}, makeRolePlayer("").roles)
+	player := makeRolePlayer("Captain; handler")
+	assert.Equal(t, []string{"Captain", "handler"}, player.roles)
+	assert.True(t, player.hasRole("captain"))
+	assert.False(t, player.hasRole("cutter"))
+}
+
+func TestRoleCriterion(t *testing.T) {
+	for _, argument := range []string{"", "captain", ":1:1", "captain:x:", "captain:2:1"} {
+		_, err := newRoleCriterion("bad", argument)
+		assert.NotNil(t, err, argument)
+	}
+
+	// Exactly one captain per team
+	scorer, err := newRoleCriterion("captain per team", "captain:1:1")
+	assert.Nil(t, err)
+	teams := make([]Team, numTeams)
+	for i := range teams {
+		teams[i].players = []Player{makeRolePlayer("captain"), makeRolePlayer("")}
+	}
+	assert.Equal(t, Score(0), scorer.Score(teams))
+	teams[0].players = append(teams[0].players, teams[1].players[0])
+	teams[1].players = teams[1].players[1:]
+	assert.Equal(t, []float64{2, 0, 1, 1, 1, 1}, scorer.RawValues(teams))
+	assert.Equal(t, Score(2), scorer.Score(teams))
+
+	// At least two handlers, with no maximum
+	scorer, err = newRoleCriterion("handler per team", "handler:2:")
+	assert.Nil(t, err)
+	players := []Player{makeRolePlayer("handler"), makeRolePlayer("handler"),
+		makeRolePlayer("handler")}
+	assert.Equal(t, Score(2*(numTeams-1)),
+		scorer.(roleCriterion).boundFunction()(players))
+}
@@ -10,12 +10,14 @@ import (
 	"os/signal"
 	"runtime"
 	"sort"
+	"strings"
 	"syscall"
 	"text/tabwriter"
 	"time"
 
 	"github.com/op/go-logging"
 	"github.com/pkg/profile"
+	"github.com/topher200/baseutil"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 )
@@ -122,7 +124,11 @@ func PrintTeams(solution Solution) {
 			string := ""
 			for _, team := range teams {
 				if len(team.players) > i {
-					string += fmt.Sprintf("|%s\t", team.players[i].String())
+					string += fmt.Sprintf("|%s", team.players[i].String())
+					if roles := team.players[i].roles; len(roles) > 0 {
+						string += fmt.Sprintf(" (%s)", strings.Join(roles, ", "))
+					}
+					string += "\t"
 				} else {
 					string += "|\t"
 				}
@@ -134,31 +140,39 @@ func PrintTeams(solution Solution) {
 	writer.Flush()
 }
 
-// Mutate the solution by moving random players to random teams, sometimes.
-func mutate(players []Player) {
+// Mutate the solution with random mutation operators, sometimes.
+func mutate(engine *scoringEngine) {
+	// Only scored when we're tracking mutation stats, and a mutation happens
+	var score Score
+	scored := false
 	for {
 		// We have mutationChance of mutating. Otherwise, we break out of our loop
 		if rand.Intn(100) > mutationChance {
 			return
 		}
-		// Mutation! Move a random player to a random new team
-		players[rand.Intn(len(players))].team = uint8(rand.Intn(numTeams))
+		if trackMutationStats && !scored {
+			score = engine.score()
+			scored = true
+		}
+		// Mutation! Move or swap some players
+		operator := applyMutationOperator(engine)
+		if trackMutationStats && operator != nil {
+			newScore := engine.score()
+			operator.record(score, newScore)
+			score = newScore
+		}
 	}
 }
 
-// Breed via combining the two given solutions, then randomly mutating.
-func breed(solution1 Solution, solution2 Solution) Solution {
-	// Create the new solution by taking crossover from both inputs
+// twoPointCrossover combines the two given solutions by taking a random run of
+// players from solution2 and the rest from solution1.
+func twoPointCrossover(solution1 Solution, solution2 Solution) []Player {
 	newPlayers := make([]Player, len(solution1.players))
 
 	// Split the genomes in two random places. Take players until splitIndex1 from
 	// solution1, then players until splitIndex2 from solution2, then fill out
 	// from solution1.
 	numPlayers := len(solution1.players)
-	if numPlayers <= 2 {
-		fmt.Printf("Error: not enough players (%v) to breed\n", numPlayers)
-		return solution1
-	}
 	splitIndex1 := rand.Intn(numPlayers - 2)
 	splitIndex2 := numPlayers
 	if splitIndex1 > 1 {
@@ -173,21 +187,51 @@ func breed(solution1 Solution, solution2 Solution) Solution {
 	for i := splitIndex2; i < numPlayers; i++ {
 		newPlayers[i] = solution1.players[i]
 	}
+	return newPlayers
+}
+
+// crossover is the operator breed uses to combine two solutions. Set from the
+// command line.
+var crossover = twoPointCrossover
+
+// Breed via combining the two given solutions, then randomly mutating.
+func breed(index *rosterIndex, solution1 Solution, solution2 Solution) Solution {
+	numPlayers := len(solution1.players)
+	if numPlayers <= 2 {
+		fmt.Printf("Error: not enough players (%v) to breed\n", numPlayers)
+		return solution1
+	}
+
+	// Create the new solution by taking crossover from both inputs
+	newPlayers := crossover(solution1, solution2)
 
 	// Mutate the new player list
-	mutate(newPlayers)
+	engine := newScoringEngine(index, newPlayers)
+	mutate(engine)
+
+	return Solution{newPlayers, engine.score()}
+}
 
-	solutionScore, _ := ScoreSolution(newPlayers)
-	return Solution{newPlayers, solutionScore}
+// randomSolutions creates solutions with each player on a random team
+func randomSolutions(players []Player, numSolutions int) []Solution {
+	solutions := make([]Solution, numSolutions)
+	for i, _ := range solutions {
+		ourPlayers := make([]Player, len(players))
+		copy(ourPlayers, players)
+		randomizeTeams(ourPlayers)
+		solutionScore, _ := ScoreSolution(ourPlayers)
+		solutions[i] = Solution{ourPlayers, solutionScore}
+	}
+	return solutions
 }
 
 type workerTask struct {
 	parent1, parent2 Solution
 }
 
-func worker(tasks <-chan workerTask, results chan<- Solution) {
+func worker(index *rosterIndex, tasks <-chan workerTask, results chan<- Solution) {
 	for task := range tasks {
-		results <- breed(task.parent1, task.parent2)
+		results <- breed(index, task.parent1, task.parent2)
 	}
 }
 
@@ -227,19 +271,82 @@ func performRun(
 	return solutions
 }
 
+// options holds the user's choices from the command line
+type options struct {
+	// "optimize", "draft" to compare the optimized rosters with a draft, or
+	// "assist" to advise on a live draft
+	command string
+	// how to run the draft
+	draftSettings draftSettings
+	// how hard to work after each pick when the command is "assist"
+	assistSettings assistSettings
+	// whether or not we should be profiling
+	profiling bool
+	// the number of CPUs to use for goroutines, which is manipulated by "-d"
+	numWorkers int
+	// whether to explain each player's placement after the run
+	explain bool
+	// whether to polish the best solution with a local search after the run
+	polish bool
+	// which search strategy to use: "genetic", "anneal" or "exact"
+	solver string
+	// how to cool down when the solver is "anneal"
+	annealSchedule annealSchedule
+	// how long the "exact" solver may search for
+	timeLimit time.Duration
+	// how to run the genetic algorithm as separate islands
+	islandSettings islandSettings
+	// when to restart the genetic algorithm
+	diversitySettings diversitySettings
+	// number of best parents that carry over to the next generation
+	elitism int
+	// what the "pareto" solver trades off, and for how long
+	paretoSettings paretoSettings
+	// number of different rosters to show, and how many players apart they
+	// must be
+	numAlternatives, minDistance int
+	// how to find the worst case of each criterion
+	normalization        string
+	normalizationSamples int
+	scales               map[string]Score
+}
+
 // parseCommandLine parses the user input
 //
 // Returns:
 //  - a []Player of the players from the input file
-//  - a bool which tells us whether or not we should be profiling
-//  - the number of CPUs to use for goroutines, which is manipulated by "-d"
-func parseCommandLine() ([]Player, bool, int) {
-	filenamePointer := kingpin.Arg("players",
-		"filename from which to get list of players").
-		Required().String()
-	baggagesPointer := kingpin.Arg("baggages",
-		"filename from which to get list of baggages").
-		Required().String()
+//  - the options the user chose
+func parseCommandLine() ([]Player, options) {
+	optimizeCommand := kingpin.Command("optimize",
+		"make the most balanced rosters (the default)").Default()
+	draftCommand := kingpin.Command("draft",
+		"simulate a captains' draft, and compare its rosters with the optimized ones")
+	assistCommand := kingpin.Command("assist",
+		"advise on each pick during a live captains' draft")
+	var filename, baggagesFilename string
+	for _, command := range []*kingpin.CmdClause{
+		optimizeCommand, draftCommand, assistCommand} {
+		command.Arg("players", "filename from which to get list of players").
+			Required().StringVar(&filename)
+		command.Arg("baggages", "filename from which to get list of baggages").
+			Required().StringVar(&baggagesFilename)
+	}
+	var draftOrder string
+	for _, command := range []*kingpin.CmdClause{draftCommand, assistCommand} {
+		command.Flag("order",
+			"the order teams pick in: \"snake\" reverses it every round").
+			Default("snake").EnumVar(&draftOrder, "snake", "linear")
+	}
+	draftStrategyPointer := draftCommand.Flag("strategy",
+		"how teams pick: \"best\" available player, or the best available of "+
+			"a gender the team \"needs\", bringing along their baggages").
+		Default("needs").Enum("best", "needs")
+	assistRestartsPointer := assistCommand.Flag("restarts",
+		"number of random starting points to polish after each pick").
+		Default("3").Int()
+	assistSuggestionsPointer := assistCommand.Flag("suggestions",
+		"number of players to suggest for the team on the clock").
+		Default("10").Int()
 	deterministicPointer := kingpin.Flag("deterministic",
 		"makes our output deterministic by allowing the default rand.Seed").
 		Short('d').Bool()
@@ -247,10 +354,116 @@ func parseCommandLine() ([]Player, bool, int) {
 		"output profiling stats when true").Short('p').Bool()
 	verbosePointer := kingpin.Flag("verbose",
 		"verbose output").Short('v').Bool()
-	kingpin.Parse()
+	explainPointer := kingpin.Flag("explain",
+		"explain how each criterion would change if each player moved teams").
+		Short('e').Bool()
+	polishPointer := kingpin.Flag("polish",
+		"polish the final solution by trying every player move and swap").
+		Default("true").Bool()
+	crossoverPointer := kingpin.Flag("crossover",
+		"how to combine parents: twopoint (splice runs of players) or team "+
+			"(align the parents' teams, then inherit whole teams)").
+		Default("twopoint").Enum("twopoint", "team")
+	mutationWeightsPointer := kingpin.Flag("mutation-weights",
+		"relative chance of each mutation operator: move, swap, gender-swap "+
+			"and rating-swap").
+		Default("move=1,swap=1,gender-swap=1,rating-swap=1").String()
+	solverPointer := kingpin.Flag("solver",
+		"search strategy: genetic (a genetic algorithm) or anneal (simulated "+
+			"annealing, using the mutation operators as its moves) or exact "+
+			"(branch and bound, for small leagues) or pareto (trade off a few "+
+			"criteria against each other, showing several rosters to choose from)").
+		Default("genetic").Enum("genetic", "anneal", "exact", "pareto")
+	paretoCriteriaPointer := kingpin.Flag("pareto-criteria",
+		"comma separated criteria for the pareto solver to trade off. All other "+
+			"criteria are added together as one more").
+		Default("matching baggages,number of males,average rating players").String()
+	paretoSizePointer := kingpin.Flag("pareto-size",
+		"number of trade-off rosters for the pareto solver to show").
+		Default("5").Int()
+	paretoGenerationsPointer := kingpin.Flag("pareto-generations",
+		"number of generations for the pareto solver to run").Default("1000").Int()
+	criteriaPointer := kingpin.Flag("criteria",
+		"csv file of the criteria to score with, instead of the built-in ones. "+
+			"Columns are Name, Criterion (one of "+strings.Join(CriterionKinds(), ", ")+
+			"), Filter (Male, Female or All), Top Players, Weight and Argument").String()
+	spreadByPointer := kingpin.Flag("spread-by",
+		"column of the players file to spread evenly across the teams, like "+
+			"a club or an age band. Can be repeated").Strings()
+	previousRostersPointer := kingpin.Flag("previous-roster",
+		"csv file of a past season's rosters, with columns \"First Name\", "+
+			"\"Last Name\" and \"Team\", to avoid reuniting teammates. Can be "+
+			"repeated, from the most recent season back").Strings()
+	previousDecayPointer := kingpin.Flag("previous-decay",
+		"how much less each past season counts than the one after it").
+		Default("0.5").Float64()
+	roleConstraintsPointer := kingpin.Flag("role-constraints",
+		"csv file of how many players of each role (from the players file's "+
+			"\"Role\" column) a team should have, with columns \"Role\", "+
+			"\"Min\" and \"Max\"").String()
+	positionQuotasPointer := kingpin.Flag("position-quotas",
+		"csv file of how many players of each position (from the players "+
+			"file's \"Positions\" column) a team needs, with columns "+
+			"\"Position\" and \"Count\"").String()
+	availabilityPointer := kingpin.Flag("availability",
+		"csv file of which dates each player can attend, with columns "+
+			"\"First Name\", \"Last Name\" and one for each date").String()
+	normalizationPointer := kingpin.Flag("normalization",
+		"how to find each criterion's worst case, which its score is divided by: "+
+			"sample (the worst of some random solutions), analytic (the worst "+
+			"possible) or fixed (read from --scales)").
+		Default("sample").Enum("sample", "analytic", "fixed")
+	normalizationSamplesPointer := kingpin.Flag("normalization-samples",
+		"number of random solutions to sample for the worst cases").
+		Default("1000").Int()
+	scalesPointer := kingpin.Flag("scales",
+		"csv file with each criterion's worst case, for fixed normalization. "+
+			"Columns are Criterion and Scale").String()
+	alternativesPointer := kingpin.Flag("alternatives",
+		"number of good, but different, rosters to show").Default("1").Int()
+	minDistancePointer := kingpin.Flag("min-distance",
+		"minimum number of players placed differently between alternatives").
+		Default("3").Int()
+	startTemperaturePointer := kingpin.Flag("anneal-start-temperature",
+		"temperature to start annealing at").Default("10").Float64()
+	endTemperaturePointer := kingpin.Flag("anneal-end-temperature",
+		"temperature to finish annealing at").Default("0.01").Float64()
+	annealStepsPointer := kingpin.Flag("anneal-steps",
+		"number of moves to try while annealing").Default("1000000").Int()
+	timeLimitPointer := kingpin.Flag("time-limit",
+		"longest time the exact solver may search for").Default("60s").Duration()
+	islandsPointer := kingpin.Flag("islands",
+		"number of separate populations for the genetic algorithm to evolve, "+
+			"each on its own goroutine").Default("1").Int()
+	migrationIntervalPointer := kingpin.Flag("migration-interval",
+		"number of generations between migrations from island to island").
+		Default("50").Int()
+	migrationSizePointer := kingpin.Flag("migration-size",
+		"number of each island's best solutions that migrate to the next island").
+		Default("2").Int()
+	minDiversityPointer := kingpin.Flag("min-diversity",
+		"restart the genetic algorithm, keeping only the best solutions, when "+
+			"the parents average fewer than this many players apart. 0 disables").
+		Default("1").Float64()
+	numElitePointer := kingpin.Flag("restart-elite",
+		"number of best solutions to keep through a restart").Default("2").Int()
+	elitismPointer := kingpin.Flag("elitism",
+		"number of best parents that compete with their children to be the "+
+			"next generation's parents").Default("1").Int()
+	baggagePolicyPointer := kingpin.Flag("baggage-policy",
+		"which baggages to honor: all, mutual (only when both players asked), or "+
+			"symmetrize (one-way requests count for both players)").
+		Default("all").Enum("all", "mutual", "symmetrize")
+	maxBaggagesPointer := kingpin.Flag("max-baggages-per-player",
+		"drop any baggages past this many per player. 0 means no limit").Int()
+	maxBaggageGroupPointer := kingpin.Flag("max-baggage-group",
+		"warn about chains of baggages larger than this. Defaults to the number "+
+			"of players per team").Int()
+	command := kingpin.Parse()
 
 	// Set up logging
 	logging.SetBackend(logging.NewLogBackend(os.Stdout, "", 0))
+	trackMutationStats = *verbosePointer
 	if *verbosePointer {
 		logging.SetLevel(logging.DEBUG, "")
 	} else {
@@ -266,9 +479,119 @@ func parseCommandLine() ([]Player, bool, int) {
 		numWorkers = 1
 	}
 
-	players := ParsePlayers(*filenamePointer)
-	ParseBaggages(*baggagesPointer, players)
-	return players, *runProfilingPointer, numWorkers
+	if *crossoverPointer == "team" {
+		crossover = teamCrossover
+	}
+	baseutil.Check(SetMutationWeights(*mutationWeightsPointer))
+	if *previousDecayPointer <= 0 || *previousDecayPointer > 1 {
+		baseutil.Check(fmt.Errorf("--previous-decay must be above 0 and at most 1"))
+	}
+	teammateDecay = *previousDecayPointer
+	if *criteriaPointer != "" {
+		criteria, err := ParseCriteria(*criteriaPointer)
+		baseutil.Check(err)
+		baseutil.Check(SetCriteria(criteria))
+	}
+	for _, column := range *spreadByPointer {
+		criterion, err := newCriterion("spread of "+column, "category", column,
+			nil, 0, categoryWeight)
+		baseutil.Check(err)
+		baseutil.Check(SetCriteria(append(criteriaToScore, criterion)))
+	}
+	if *roleConstraintsPointer != "" {
+		criteria, err := ParseRoleConstraints(*roleConstraintsPointer)
+		baseutil.Check(err)
+		baseutil.Check(SetCriteria(append(criteriaToScore, criteria...)))
+	}
+	if *positionQuotasPointer != "" {
+		criteria, err := PositionCriteria(*positionQuotasPointer)
+		baseutil.Check(err)
+		baseutil.Check(SetCriteria(append(criteriaToScore, criteria...)))
+	}
+	if *availabilityPointer != "" {
+		criteria, err := AvailabilityCriteria(*availabilityPointer)
+		baseutil.Check(err)
+		baseutil.Check(SetCriteria(append(criteriaToScore, criteria...)))
+	}
+	if len(*previousRostersPointer) > 0 {
+		baseutil.Check(SetCriteria(append(criteriaToScore,
+			TeammatesCriterion(*previousRostersPointer))))
+	}
+	if command == draftCommand.FullCommand() && *solverPointer == "pareto" {
+		baseutil.Check(fmt.Errorf("a draft can't be compared with the pareto solver"))
+	}
+	// Only the genetic algorithm remembers the rosters it came across
+	if *alternativesPointer > 1 && *solverPointer != "genetic" {
+		baseutil.Check(fmt.Errorf("--alternatives needs the genetic solver"))
+	}
+	if *assistRestartsPointer < 0 || *assistSuggestionsPointer < 0 {
+		baseutil.Check(fmt.Errorf("--restarts and --suggestions can't be negative"))
+	}
+	if *elitismPointer < 0 {
+		baseutil.Check(fmt.Errorf("--elitism can't be negative"))
+	}
+	if *islandsPointer < 1 || *migrationSizePointer < 0 {
+		baseutil.Check(fmt.Errorf("there must be at least one island, and " +
+			"--migration-size can't be negative"))
+	}
+	if *normalizationSamplesPointer < 1 {
+		baseutil.Check(fmt.Errorf("--normalization-samples must be at least 1"))
+	}
+	if *endTemperaturePointer <= 0 || *startTemperaturePointer < *endTemperaturePointer {
+		baseutil.Check(fmt.Errorf("the anneal temperatures must be above 0, and " +
+			"the end temperature can't be above the start temperature"))
+	}
+	var paretoCriteria []int
+	if *solverPointer == "pareto" {
+		var err error
+		paretoCriteria, err = ParseParetoCriteria(*paretoCriteriaPointer)
+		baseutil.Check(err)
+	}
+	var scales map[string]Score
+	if *scalesPointer != "" {
+		scales = ParseScales(*scalesPointer)
+	} else if *normalizationPointer == "fixed" {
+		baseutil.Check(fmt.Errorf("fixed normalization needs a --scales file"))
+	}
+
+	players := ParsePlayers(filename)
+	ParseBaggages(baggagesFilename, players)
+	// Requests past the limit are dropped before the policy can add returning
+	// baggages for them, or warn that they're one-way
+	EnforceMaxBaggages(players, *maxBaggagesPointer)
+	ValidateBaggages(players)
+	baseutil.Check(ValidateCategories(players))
+	baseutil.Check(ValidateRoles(players))
+	baseutil.Check(ValidateAvailability(players))
+	baseutil.Check(CheckPositionFeasibility(players))
+	baggagePolicy, err := StringToBaggagePolicy(*baggagePolicyPointer)
+	baseutil.Check(err)
+	ApplyBaggagePolicy(players, baggagePolicy)
+	AnalyzeBaggageGroups(players, *maxBaggageGroupPointer)
+	return players, options{
+		profiling:  *runProfilingPointer,
+		numWorkers: numWorkers,
+		explain:    *explainPointer,
+		polish:     *polishPointer,
+		solver:     *solverPointer,
+		annealSchedule: annealSchedule{
+			*startTemperaturePointer, *endTemperaturePointer, *annealStepsPointer},
+		timeLimit: *timeLimitPointer,
+		islandSettings: islandSettings{
+			*islandsPointer, *migrationIntervalPointer, *migrationSizePointer},
+		diversitySettings: diversitySettings{*minDiversityPointer, *numElitePointer},
+		elitism:           *elitismPointer,
+		paretoSettings: paretoSettings{
+			paretoCriteria, *paretoGenerationsPointer, *paretoSizePointer},
+		numAlternatives:      *alternativesPointer,
+		minDistance:          *minDistancePointer,
+		normalization:        *normalizationPointer,
+		normalizationSamples: *normalizationSamplesPointer,
+		scales:               scales,
+		command:              command,
+		draftSettings:        draftSettings{draftOrder, *draftStrategyPointer},
+		assistSettings:       assistSettings{*assistRestartsPointer, *assistSuggestionsPointer},
+	}
 }
 
 func timeToClose(
@@ -283,48 +606,29 @@ func timeToClose(
 	return numRunsCompleted > topScoreRunNumber+10000
 }
 
-func main() {
-	players, profilingOn, numWorkers := parseCommandLine()
-	startTime := time.Now()
-	if len(players) == 0 {
-		panic("Could not find players")
-	}
-
-	// Start profiler
-	if profilingOn {
-		newLog.Info("Running profiler")
-		defer profile.Start(profile.CPUProfile, profile.ProfilePath(".")).Stop()
-	}
-
-	// Create random Parent solutions to start
-	parentSolutions := make([]Solution, numParents)
-	for i, _ := range parentSolutions {
-		ourPlayers := make([]Player, len(players))
-		copy(ourPlayers, players)
-		randomizeTeams(ourPlayers)
-		solutionScore, _ := ScoreSolution(ourPlayers)
-		parentSolutions[i] = Solution{ourPlayers, solutionScore}
-	}
-
-	// Use the random starting solutions to determine the worst case for each of
-	// our criteria
-	PopulateWorstCases(parentSolutions)
-
+// runGeneticAlgorithm breeds generations of solutions, starting from the given
+// parents, until we stop finding better solutions or are told to stop. The
+// best numElite parents of each generation compete with their children. Each
+// generation's parents are added to the hall of fame.
+//
+// Returns the best solution found.
+func runGeneticAlgorithm(index *rosterIndex, parentSolutions []Solution,
+	numWorkers int, numElite int, diversity diversitySettings, hall *hallOfFame,
+	doneSignal <-chan os.Signal) Solution {
 	// Start our worker goroutines
 	tasks := make(chan workerTask, numSolutionsPerRun)
 	results := make(chan Solution, numSolutionsPerRun)
 	for i := 0; i < numWorkers; i++ {
-		go worker(tasks, results)
+		go worker(index, tasks, results)
 	}
 	defer close(tasks)
 
-	// Allow user to signal exit
-	doneSignal := make(chan os.Signal, 1)
-	signal.Notify(doneSignal, syscall.SIGINT)
-
+	sort.Sort(ByScore(parentSolutions))
 	topScore := parentSolutions[0].score
 	numRunsCompleted := 0
 	topScoreRunNumber := 0
+	parentDiversity := PopulationDiversity(parentSolutions)
+	numRestarts := 0
 	for {
 		// If we have a new best score, save and print it!
 		if topScore != parentSolutions[0].score {
@@ -335,14 +639,25 @@ func main() {
 					numRunsCompleted, topScore)
 				PrintTeams(parentSolutions[0])
 				PrintSolutionScoring(parentSolutions[0])
+				newLog.Info(MutationStats())
+				newLog.Info("Parent diversity: %.02f players apart", parentDiversity)
 			}
 		}
 
 		// Create new solutions, and save the best ones
 		newSolutions := performRun(parentSolutions, tasks, results)
-		sort.Sort(ByScore(newSolutions))
-		for i, _ := range parentSolutions {
-			parentSolutions[i] = newSolutions[i]
+		copy(parentSolutions, selectParents(parentSolutions, newSolutions, numElite))
+		for _, solution := range parentSolutions {
+			hall.add(solution)
+		}
+
+		// If the parents have all converged, bring in some new blood
+		var restarted bool
+		parentDiversity, restarted = diversity.maintain(parentSolutions)
+		if restarted {
+			numRestarts += 1
+			newLog.Debug("Parent diversity fell to %.02f on run %d. Restarting with "+
+				"the %d best solutions", parentDiversity, numRunsCompleted, diversity.numElite)
 		}
 
 		numRunsCompleted += 1
@@ -351,11 +666,110 @@ func main() {
 		}
 	}
 
-	// Display our solution to the user
-	topSolution := parentSolutions[0]
 	fmt.Printf("Exiting after %d runs. Top score was found on run #%d\n",
 		numRunsCompleted, topScoreRunNumber)
+	fmt.Printf("Restarted %d times after the parents converged\n", numRestarts)
+	if trackMutationStats {
+		fmt.Println(MutationStats())
+	}
+	return parentSolutions[0]
+}
+
+func main() {
+	players, opts := parseCommandLine()
+	startTime := time.Now()
+	if len(players) == 0 {
+		panic("Could not find players")
+	}
+
+	// Start profiler
+	if opts.profiling {
+		newLog.Info("Running profiler")
+		defer profile.Start(profile.CPUProfile, profile.ProfilePath(".")).Stop()
+	}
+
+	// Determine the worst case for each of our criteria
+	baseutil.Check(Normalize(
+		opts.normalization, players, opts.normalizationSamples, opts.scales))
+
+	if opts.command == "assist" {
+		RunAssistant(players, opts.draftSettings, opts.assistSettings, os.Stdin)
+		return
+	}
+
+	// Create random Parent solutions to start
+	parentSolutions := randomSolutions(players, numParents)
+	index := newRosterIndex(players)
+
+	// Allow user to signal exit
+	doneSignal := make(chan os.Signal, 1)
+	signal.Notify(doneSignal, syscall.SIGINT)
+
+	var topSolution Solution
+	hall := newHallOfFame(hallOfFameSize)
+	switch opts.solver {
+	case "anneal":
+		topSolution = Anneal(index, parentSolutions[0], opts.annealSchedule, doneSignal)
+	case "pareto":
+		rosters := SearchPareto(index, players, opts.paretoSettings, doneSignal)
+		PrintParetoFront(rosters, opts.paretoSettings.criteria)
+		return
+	case "exact":
+		result, err := SolveExactly(players, opts.timeLimit, doneSignal)
+		baseutil.Check(err)
+		if result.proven {
+			fmt.Printf("Proven optimal rating deviation: %.02f (searched %d nodes)\n",
+				result.objective, result.numNodes)
+		} else {
+			fmt.Printf("Best rating deviation found: %.02f, which is at least %.02f "+
+				"(searched %d nodes before stopping)\n",
+				result.objective, result.lowerBound, result.numNodes)
+		}
+		topSolution = result.solution
+	default:
+		if opts.islandSettings.numIslands > 1 {
+			topSolution = runIslands(index, players, opts.islandSettings,
+				opts.numWorkers, opts.elitism, opts.diversitySettings, hall, doneSignal)
+			break
+		}
+		topSolution = runGeneticAlgorithm(index, parentSolutions, opts.numWorkers,
+			opts.elitism, opts.diversitySettings, hall, doneSignal)
+	}
+
+	// Display our solution to the user. The exact solver's solution is optimal
+	// for its own objective, so polishing it for ours would hide that.
+	if opts.polish && opts.solver != "exact" {
+		polishedSolution := Polish(topSolution)
+		fmt.Printf("Polishing improved the score by %.02f (from %.02f to %.02f)\n",
+			topSolution.score-polishedSolution.score, topSolution.score,
+			polishedSolution.score)
+		topSolution = polishedSolution
+	}
 	PrintTeams(topSolution)
 	PrintSolutionScoring(topSolution)
+	fmt.Printf("Rating deviation (the exact solver's objective): %.02f\n",
+		RatingDeviation(topSolution.players))
+	if opts.explain {
+		PrintPlacementExplanations(topSolution)
+	}
+	if opts.numAlternatives > 1 {
+		hall.add(topSolution)
+		alternatives := ChooseAlternatives(
+			hall.solutions, opts.numAlternatives, opts.minDistance)
+		if len(alternatives) < opts.numAlternatives {
+			fmt.Printf("\nOnly found %d rosters at least %d players apart\n",
+				len(alternatives), opts.minDistance)
+		}
+		PrintAlternatives(alternatives)
+	}
+	if opts.command == "draft" {
+		drafted := Draft(players, opts.draftSettings)
+		fmt.Printf("\nSimulated a %s draft, with the \"%s\" strategy\n",
+			opts.draftSettings.order, opts.draftSettings.strategy)
+		PrintTeams(drafted)
+		PrintSolutionScoring(drafted)
+		fmt.Println()
+		PrintDraftComparison(drafted, topSolution)
+	}
 	newLog.Debug("Program runtime: %.02fs", time.Since(startTime).Seconds())
 }
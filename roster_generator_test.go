@@ -10,8 +10,10 @@ func TestSplitIntoTeams(t *testing.T) {
 	// todo test remove 2
 	players := make([]Player, 2)
 
-	players[0] = Player{Name{"Team 1", "Player"}, 100, Male, 1, []Name{}}
-	players[1] = Player{Name{"Team 2", "Player"}, 100, Male, 2, []Name{}}
+	players[0] = Player{name: Name{"Team 1", "Player"}, rating: 100, gender: Male, team: 1,
+		baggages: []Name{}}
+	players[1] = Player{name: Name{"Team 2", "Player"}, rating: 100, gender: Male, team: 2,
+		baggages: []Name{}}
 
 	teams := splitIntoTeams(players)
 
@@ -5,9 +5,8 @@ import (
 	"math"
 	"os"
 	"sort"
+	"sync"
 	"text/tabwriter"
-
-	"github.com/GaryBoone/GoStats/stats"
 )
 
 // a criterionCalculationFunction returns two values: a Score, and the raw score
@@ -16,40 +15,82 @@ import (
 // shows the individual values for each team. If the raw score for each team for
 // that criterion doesn't make much sense, it's an empty slice.
 type criterionCalculationFunction func(teams []Team) (Score, []float64)
+
+// a criterionAggregateFunction calculates the same raw Score as its
+// criterionCalculationFunction, but from the running totals kept for each team
+// by a scoringEngine.
+type criterionAggregateFunction func(teams []teamAggregate) Score
+
+// a criterionBoundFunction returns the largest raw Score the criterion could
+// have, given the (already filtered) players
+type criterionBoundFunction func(players []Player) Score
 type criterion struct {
-	name      string                       // human readable name
-	calculate criterionCalculationFunction // how to calculate the raw score
-	filter    PlayerFilter                 // cull down to players that match
+	name   string    // human readable name
+	scorer Criterion // how to calculate the raw score
+	// how to calculate the raw score from per-team totals. If nil, the
+	// scoringEngine falls back to scorer.
+	aggregate criterionAggregateFunction
+	bound     criterionBoundFunction // used for analytic normalization, if not nil
+	filter    PlayerFilter           // cull down to players that match
 	// numPlayers reduces the amount of players we analyze from each team.
 	// Sometimes used to just grab the top players on the team, for example.
 	// Ignored if 0.
 	numPlayers int
 	weight     int // how much weight to give this score
 	// worstCase is calculated at runtime to be the absolute worst score we can
-	// see this criterion getting, calculated using random sampling
+	// see this criterion getting, calculated by random sampling, analytically
+	// or read from a file
 	worstCase Score
 }
 
-var criteriaToScore = [...]criterion{
-	criterion{"matching baggages", baggagesMatch, nil, 0, 10000, 0},
-	criterion{"number of players", playerCountDifference, nil, 0, 8, 0},
-	criterion{"number of males", playerCountDifference, IsMale, 0, 1200, 0},
-	criterion{"number of females", playerCountDifference, IsFemale, 0, 1200, 0},
+// criteriaToScore are the built-in criteria, unless replaced by SetCriteria
+var criteriaToScore = []criterion{
+	mustNewCriterion("matching baggages", "baggages", nil, 0, 10000),
+	mustNewCriterion("number of players", "count", nil, 0, 8),
+	mustNewCriterion("number of males", "count", IsMale, 0, 1200),
+	mustNewCriterion("number of females", "count", IsFemale, 0, 1200),
+
+	mustNewCriterion("average rating players", "average", nil, 0, 8),
+	mustNewCriterion("std dev of team player ratings", "stddev", nil, 0, 6),
+
+	mustNewCriterion("average rating males", "average", IsMale, 0, 7),
+	mustNewCriterion("std dev of team male ratings", "stddev", IsMale, 0, 5),
+	mustNewCriterion("average rating top males", "average", IsMale, 3, 5),
+	mustNewCriterion("std dev of top male ratings", "stddev", IsMale, 3, 5),
 
-	criterion{"average rating players", ratingDifference, nil, 0, 8, 0},
-	criterion{"std dev of team player ratings", ratingStdDev, nil, 0, 6, 0},
+	mustNewCriterion("average rating females", "average", IsFemale, 0, 7),
+	mustNewCriterion("std dev of team female ratings", "stddev", IsFemale, 0, 5),
+	mustNewCriterion("average rating top females", "average", IsFemale, 2, 7),
+	mustNewCriterion("std dev of top female ratings", "stddev", IsFemale, 2, 5),
+}
+
+// Weighted score given to a criterion whose raw score is NaN. It's larger than
+// any real score, so solutions with NaN sort after the rest.
+const nanPenalty = Score(1e9)
 
-	criterion{"average rating males", ratingDifference, IsMale, 0, 7, 0},
-	criterion{"std dev of team male ratings", ratingStdDev, IsMale, 0, 5, 0},
-	criterion{"average rating top males", ratingDifference, IsMale, 3, 5, 0},
-	criterion{"std dev of top male ratings", ratingStdDev, IsMale, 3, 5, 0},
+// names of the criteria we've already warned about scoring NaN
+var nanCriteria sync.Map
 
-	criterion{"average rating females", ratingDifference, IsFemale, 0, 7, 0},
-	criterion{"std dev of team female ratings", ratingStdDev, IsFemale, 0, 5, 0},
-	criterion{"average rating top females", ratingDifference, IsFemale, 2, 7, 0},
-	criterion{"std dev of top female ratings", ratingStdDev, IsFemale, 2, 5, 0},
+// sampleStandardDeviation of the values. Fewer than two values have a standard
+// deviation of 0, rather than NaN.
+func sampleStandardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+	// Summing squared distances from the mean can't go below zero
+	sumSquares := 0.0
+	for _, value := range values {
+		sumSquares += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(sumSquares / float64(len(values)-1))
 }
 
+// playerCountDifference counts empty teams as having 0 players
 func playerCountDifference(teams []Team) (Score, []float64) {
 	// Score increases as the different in team length becomes greater than 1
 	min := len(teams[0].players)
@@ -72,14 +113,57 @@ func playerCountDifference(teams []Team) (Score, []float64) {
 	return Score(score), []float64{}
 }
 
+func playerCountDifferenceAggregate(teams []teamAggregate) Score {
+	min := teams[0].count
+	max := teams[0].count
+	for _, team := range teams {
+		if team.count < min {
+			min = team.count
+		}
+		if team.count > max {
+			max = team.count
+		}
+	}
+	score := max - min - 1
+	if score < 0 {
+		score = 0
+	}
+	return Score(score)
+}
+
+// playerCountDifferenceBound is the difference when everybody is on one team
+func playerCountDifferenceBound(players []Player) Score {
+	return maxScore(0, Score(len(players)-1))
+}
+
+// ratingDifference gives empty teams an average rating of 0, which heavily
+// penalizes leaving a team without any of the filtered players. A team of one
+// averages that player's rating.
 func ratingDifference(teams []Team) (Score, []float64) {
 	teamAverageRatings := make([]float64, numTeams)
 	for i, team := range teams {
 		teamAverageRatings[i] = float64(AverageRating(team))
 	}
-	return Score(stats.StatsSampleStandardDeviation(teamAverageRatings)), teamAverageRatings
+	return Score(sampleStandardDeviation(teamAverageRatings)), teamAverageRatings
+}
+
+func ratingDifferenceAggregate(teams []teamAggregate) Score {
+	var teamAverageRatings [numTeams]float64
+	for i, team := range teams {
+		teamAverageRatings[i] = team.average()
+	}
+	return Score(sampleStandardDeviation(teamAverageRatings[:]))
 }
 
+// ratingDifferenceBound is the spread of team averages when half of the teams
+// average the lowest they could and half the highest. Since empty teams average
+// 0, the lowest is at most 0.
+func ratingDifferenceBound(players []Player) Score {
+	low, high := averageRange(players)
+	return Score(maxSampleStandardDeviation(low, high, numTeams))
+}
+
+// ratingStdDev gives teams of less than 2 players a standard deviation of 0
 func ratingStdDev(teams []Team) (Score, []float64) {
 	teamRatingsStdDev := make([]float64, numTeams)
 	for i, team := range teams {
@@ -91,9 +175,74 @@ func ratingStdDev(teams []Team) (Score, []float64) {
 		for j, player := range team.players {
 			playerRatings[j] = float64(player.rating)
 		}
-		teamRatingsStdDev[i] = stats.StatsSampleStandardDeviation(playerRatings)
+		teamRatingsStdDev[i] = sampleStandardDeviation(playerRatings)
+	}
+	return Score(sampleStandardDeviation(teamRatingsStdDev)), teamRatingsStdDev
+}
+
+func ratingStdDevAggregate(teams []teamAggregate) Score {
+	var teamRatingsStdDev [numTeams]float64
+	for i, team := range teams {
+		teamRatingsStdDev[i] = team.sampleStandardDeviation()
+	}
+	return Score(sampleStandardDeviation(teamRatingsStdDev[:]))
+}
+
+// ratingStdDevBound is the spread of team standard deviations when half of the
+// teams have none, and half have a player at each end of the ratings
+func ratingStdDevBound(players []Player) Score {
+	low, high := ratingRange(players)
+	return Score(maxSampleStandardDeviation(0, maxSampleStandardDeviation(low, high, 2), numTeams))
+}
+
+// ratingMedianDifference is the spread of the teams' median ratings. Empty
+// teams have a median of 0, like ratingDifference.
+func ratingMedianDifference(teams []Team) (Score, []float64) {
+	teamMedianRatings := make([]float64, len(teams))
+	for i, team := range teams {
+		if len(team.players) == 0 {
+			continue
+		}
+		ratings := make([]float64, len(team.players))
+		for j, player := range team.players {
+			ratings[j] = float64(player.rating)
+		}
+		sort.Float64s(ratings)
+		middle := len(ratings) / 2
+		if len(ratings)%2 == 0 {
+			teamMedianRatings[i] = (ratings[middle-1] + ratings[middle]) / 2
+		} else {
+			teamMedianRatings[i] = ratings[middle]
+		}
+	}
+	return Score(sampleStandardDeviation(teamMedianRatings)), teamMedianRatings
+}
+
+// ratingSpread is the gap between the highest and lowest team average ratings.
+// Empty teams average 0, like ratingDifference.
+func ratingSpread(teams []Team) (Score, []float64) {
+	teamAverageRatings := make([]float64, len(teams))
+	for i, team := range teams {
+		teamAverageRatings[i] = float64(AverageRating(team))
+	}
+	low, high := valueRange(teamAverageRatings)
+	return Score(high - low), teamAverageRatings
+}
+
+func ratingSpreadAggregate(teams []teamAggregate) Score {
+	var teamAverageRatings [numTeams]float64
+	for i, team := range teams {
+		teamAverageRatings[i] = team.average()
 	}
-	return Score(stats.StatsSampleStandardDeviation(teamRatingsStdDev)), teamRatingsStdDev
+	low, high := valueRange(teamAverageRatings[:])
+	return Score(high - low)
+}
+
+// ratingSpreadBound is the gap between the lowest and highest average a team
+// could have. Since empty teams average 0, the lowest is at most 0.
+func ratingSpreadBound(players []Player) Score {
+	low, high := averageRange(players)
+	return Score(high - low)
 }
 
 func baggagesMatch(teams []Team) (Score, []float64) {
@@ -112,6 +261,23 @@ func baggagesMatch(teams []Team) (Score, []float64) {
 	return score, []float64{}
 }
 
+func baggagesMatchAggregate(teams []teamAggregate) Score {
+	score := Score(0)
+	for _, team := range teams {
+		score += Score(team.unmatchedBaggages)
+	}
+	return score
+}
+
+// baggagesMatchBound is the number of baggages, when none are matched
+func baggagesMatchBound(players []Player) Score {
+	score := Score(0)
+	for _, player := range players {
+		score += Score(len(player.baggages))
+	}
+	return score
+}
+
 func AverageRating(team Team) Score {
 	if len(team.players) == 0 {
 		return Score(0)
@@ -123,10 +289,8 @@ func AverageRating(team Team) Score {
 	return Score(sum / float32(len(team.players)))
 }
 
-// analyze criterion by filtering the input teams and running the criterion's
-// function
-func (c criterion) analyze(teams []Team) (
-	rawScore Score, normalizedScore Score, weightedScore Score, rawValues []float64) {
+// filterTeams culls each team down to the players the criterion looks at
+func (c criterion) filterTeams(teams []Team) []Team {
 	filteredTeams := make([]Team, len(teams))
 	for i, _ := range teams {
 		players := Filter(teams[i].players, c.filter)
@@ -138,15 +302,46 @@ func (c criterion) analyze(teams []Team) (
 		}
 		filteredTeams[i].players = players
 	}
+	return filteredTeams
+}
+
+// rawScore of the criterion for the teams
+func (c criterion) rawScore(teams []Team) Score {
+	return c.scorer.Score(c.filterTeams(teams))
+}
+
+// analyze criterion by filtering the input teams and running the criterion's
+// scorer
+func (c criterion) analyze(teams []Team) (
+	rawScore Score, normalizedScore Score, weightedScore Score, rawValues []float64) {
+	filteredTeams := c.filterTeams(teams)
+	if scorer, ok := c.scorer.(valuesCriterion); ok {
+		rawScore, rawValues = scorer.scoreAndValues(filteredTeams)
+	} else {
+		rawScore = c.scorer.Score(filteredTeams)
+		rawValues = c.scorer.RawValues(filteredTeams)
+	}
+	normalizedScore, weightedScore = c.weigh(rawScore)
+	return rawScore, normalizedScore, weightedScore, rawValues
+}
 
-	rawScore, rawValues = c.calculate(filteredTeams)
+// weigh normalizes the raw score against the criterion's worst case, then
+// applies the criterion's weight. A NaN raw score is given nanPenalty instead,
+// so it can't poison the total score.
+func (c criterion) weigh(rawScore Score) (normalizedScore Score, weightedScore Score) {
+	if math.IsNaN(float64(rawScore)) {
+		if _, warned := nanCriteria.LoadOrStore(c.name, true); !warned {
+			newLog.Warning("Criterion '%s' scored NaN, so it's scored %.0f instead",
+				c.name, nanPenalty)
+		}
+		return nanPenalty, nanPenalty
+	}
 	if c.worstCase != 0 {
 		normalizedScore = rawScore / c.worstCase
 	} else {
 		normalizedScore = rawScore
 	}
-	weightedScore = normalizedScore * Score(c.weight)
-	return rawScore, normalizedScore, weightedScore, rawValues
+	return normalizedScore, normalizedScore * Score(c.weight)
 }
 
 func maxScore(a, b Score) Score {
@@ -161,17 +356,22 @@ func maxScore(a, b Score) Score {
 //
 // The function has the side effect of filling in the worstCase param for each
 // criterion in criteriaToScore.
-func PopulateWorstCases(solutions []Solution) {
+//
+// Returns the number of NaN raw scores skipped for each criterion.
+func PopulateWorstCases(solutions []Solution) []int {
+	numNaN := make([]int, len(criteriaToScore))
 	for _, solution := range solutions {
 		_, rawScores := ScoreSolution(solution.players)
 		for i, criterion := range criteriaToScore {
 			if math.IsNaN(float64(rawScores[i])) {
+				numNaN[i] += 1
 				continue
 			}
 			criteriaToScore[i].worstCase = maxScore(
 				criterion.worstCase, rawScores[i])
 		}
 	}
+	return numNaN
 }
 
 // Score a solution based on all known criteria.
@@ -182,7 +382,8 @@ func ScoreSolution(players []Player) (totalScore Score, rawScores []Score) {
 	teams := splitIntoTeams(players)
 	rawScores = make([]Score, len(criteriaToScore))
 	for i, criterion := range criteriaToScore {
-		rawScore, _, weightedScore, _ := criterion.analyze(teams)
+		rawScore := criterion.rawScore(teams)
+		_, weightedScore := criterion.weigh(rawScore)
 		rawScores[i] = rawScore
 		totalScore += weightedScore
 	}
@@ -205,6 +406,9 @@ func PrintSolutionScoring(solution Solution) {
 	}
 	fmt.Println("Total score: ", totalScore)
 	writer.Flush()
+	PrintCategoryHistograms(teams)
+	PrintRepeatedTeammates(teams)
+	PrintAttendanceForecast(teams)
 
 	// Print the missing baggages
 	for _, team := range teams {
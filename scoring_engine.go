@@ -0,0 +1,251 @@
+// Incremental scoring. Keeps running totals for each team, so that moving a
+// player between teams doesn't mean rescoring the whole solution from scratch.
+
+package main
+
+import "math"
+
+// teamAggregate holds the running totals for the players on one team that
+// pass a criterion's filter
+type teamAggregate struct {
+	count           int
+	sum, sumSquares float64
+	// ratings of the players, highest first. Only kept for criteria that look
+	// at the top players of each team.
+	ratings []float32
+	// number of baggages requested by these players that aren't met by another
+	// of these players
+	unmatchedBaggages int
+}
+
+func (a teamAggregate) average() float64 {
+	if a.count == 0 {
+		return 0
+	}
+	return a.sum / float64(a.count)
+}
+
+// sampleStandardDeviation of the ratings. Teams of less than 2 players have a
+// standard deviation of 0, to match ratingStdDev.
+func (a teamAggregate) sampleStandardDeviation() float64 {
+	if a.count < 2 {
+		return 0
+	}
+	n := float64(a.count)
+	variance := (a.sumSquares - a.sum*a.sum/n) / (n - 1)
+	// Guard against rounding error pushing us just below zero
+	return math.Sqrt(math.Max(variance, 0))
+}
+
+func (a *teamAggregate) add(rating float32, keepRatings bool) {
+	a.count += 1
+	a.sum += float64(rating)
+	a.sumSquares += float64(rating) * float64(rating)
+	if !keepRatings {
+		return
+	}
+	i := 0
+	for i < len(a.ratings) && a.ratings[i] >= rating {
+		i++
+	}
+	a.ratings = append(a.ratings, 0)
+	copy(a.ratings[i+1:], a.ratings[i:])
+	a.ratings[i] = rating
+}
+
+func (a *teamAggregate) remove(rating float32, keepRatings bool) {
+	a.count -= 1
+	a.sum -= float64(rating)
+	a.sumSquares -= float64(rating) * float64(rating)
+	if !keepRatings {
+		return
+	}
+	for i := range a.ratings {
+		if a.ratings[i] == rating {
+			a.ratings = append(a.ratings[:i], a.ratings[i+1:]...)
+			return
+		}
+	}
+}
+
+// top returns the totals for only the top numPlayers players. Baggages are
+// still counted across the whole team.
+func (a teamAggregate) top(numPlayers int) teamAggregate {
+	if numPlayers <= 0 || a.count <= numPlayers {
+		return a
+	}
+	top := teamAggregate{unmatchedBaggages: a.unmatchedBaggages}
+	for _, rating := range a.ratings[:numPlayers] {
+		top.add(rating, false)
+	}
+	return top
+}
+
+// rosterIndex holds everything about a list of players that stays the same as
+// they move between teams, looked up by each player's index in the list.
+type rosterIndex struct {
+	// indices of the players that each player wants as baggage
+	baggages [][]int
+	// indices of the players that want each player as baggage
+	requestedBy [][]int
+	// number of baggages each player wants that aren't in the list of players
+	unknownBaggages []int
+	// passesFilter[c][i] is whether player i passes criteriaToScore[c].filter
+	passesFilter [][]bool
+}
+
+// newRosterIndex indexes the players. All solutions scored with the index
+// must keep their players in the same order.
+func newRosterIndex(players []Player) *rosterIndex {
+	indices := make(map[Name]int, len(players))
+	for i := len(players) - 1; i >= 0; i-- {
+		// Match FindPlayer by keeping the first player with each name
+		indices[players[i].name] = i
+	}
+
+	index := rosterIndex{
+		baggages:        make([][]int, len(players)),
+		requestedBy:     make([][]int, len(players)),
+		unknownBaggages: make([]int, len(players)),
+		passesFilter:    make([][]bool, len(criteriaToScore)),
+	}
+	for i, player := range players {
+		for _, baggage := range player.baggages {
+			j, found := indices[baggage]
+			if !found {
+				index.unknownBaggages[i] += 1
+				continue
+			}
+			if j == i {
+				// Players always match themselves
+				continue
+			}
+			index.baggages[i] = append(index.baggages[i], j)
+			requested := len(index.requestedBy[j]) > 0 &&
+				index.requestedBy[j][len(index.requestedBy[j])-1] == i
+			if !requested {
+				index.requestedBy[j] = append(index.requestedBy[j], i)
+			}
+		}
+	}
+	for c, criterion := range criteriaToScore {
+		index.passesFilter[c] = make([]bool, len(players))
+		for i, player := range players {
+			index.passesFilter[c][i] = criterion.filter == nil || criterion.filter(player)
+		}
+	}
+	return &index
+}
+
+// scoringEngine scores a solution, and keeps that score up to date as players
+// are moved between teams.
+type scoringEngine struct {
+	index *rosterIndex
+	// the solution being scored. Moves update the players' teams in place.
+	players []Player
+	// aggregates[c][t] holds the totals for criteriaToScore[c] on team t
+	aggregates [][]teamAggregate
+	// scratch space to hold the top players of each team while scoring
+	topAggregates []teamAggregate
+}
+
+// newScoringEngine calculates the totals for each team. The engine takes
+// ownership of players, and will update their teams as they move.
+func newScoringEngine(index *rosterIndex, players []Player) *scoringEngine {
+	engine := scoringEngine{
+		index:         index,
+		players:       players,
+		aggregates:    make([][]teamAggregate, len(criteriaToScore)),
+		topAggregates: make([]teamAggregate, numTeams),
+	}
+	for c, criterion := range criteriaToScore {
+		engine.aggregates[c] = make([]teamAggregate, numTeams)
+		for i, player := range players {
+			if index.passesFilter[c][i] {
+				engine.aggregates[c][player.team].add(player.rating, criterion.numPlayers > 0)
+			}
+		}
+		for i := range players {
+			engine.adjustBaggages(c, i, 1)
+		}
+	}
+	return &engine
+}
+
+// adjustBaggages adds delta to the unmatched baggage count of criterion c for
+// each of player i's unmatched requests.
+func (e *scoringEngine) adjustBaggages(c int, i int, delta int) {
+	passes := e.index.passesFilter[c]
+	if !passes[i] {
+		return
+	}
+	team := e.players[i].team
+	unmatched := e.index.unknownBaggages[i]
+	for _, j := range e.index.baggages[i] {
+		if !passes[j] || e.players[j].team != team {
+			unmatched += 1
+		}
+	}
+	e.aggregates[c][team].unmatchedBaggages += delta * unmatched
+}
+
+// move the player at index i to the given team, updating the totals
+func (e *scoringEngine) move(i int, team uint8) {
+	oldTeam := e.players[i].team
+	if oldTeam == team {
+		return
+	}
+	// Remove the baggages of anybody whose matches will change, then add them
+	// back once the player has moved
+	for c := range criteriaToScore {
+		e.adjustBaggages(c, i, -1)
+		for _, j := range e.index.requestedBy[i] {
+			e.adjustBaggages(c, j, -1)
+		}
+	}
+	rating := e.players[i].rating
+	for c, criterion := range criteriaToScore {
+		if e.index.passesFilter[c][i] {
+			e.aggregates[c][oldTeam].remove(rating, criterion.numPlayers > 0)
+			e.aggregates[c][team].add(rating, criterion.numPlayers > 0)
+		}
+	}
+	e.players[i].team = team
+	for c := range criteriaToScore {
+		e.adjustBaggages(c, i, 1)
+		for _, j := range e.index.requestedBy[i] {
+			e.adjustBaggages(c, j, 1)
+		}
+	}
+}
+
+// score returns the total score for the solution, matching ScoreSolution
+func (e *scoringEngine) score() Score {
+	return e.scoreCriteria(nil)
+}
+
+// scoreCriteria returns the total score for the solution. If weightedScores
+// isn't nil, it's filled in with the weighted score of each criterion.
+func (e *scoringEngine) scoreCriteria(weightedScores []Score) Score {
+	totalScore := Score(0)
+	var teams []Team
+	for c, criterion := range criteriaToScore {
+		var weightedScore Score
+		if criterion.aggregate == nil {
+			if teams == nil {
+				teams = splitIntoTeams(e.players)
+			}
+			_, weightedScore = criterion.weigh(criterion.rawScore(teams))
+		} else {
+			for t, aggregate := range e.aggregates[c] {
+				e.topAggregates[t] = aggregate.top(criterion.numPlayers)
+			}
+			_, weightedScore = criterion.weigh(criterion.aggregate(e.topAggregates))
+		}
+		if weightedScores != nil {
+			weightedScores[c] = weightedScore
+		}
+		totalScore += weightedScore
+	}
+	return totalScore
+}
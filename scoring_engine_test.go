@@ -0,0 +1,91 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeRandomPlayers(numPlayers int) []Player {
+	players := make([]Player, numPlayers)
+	for i := range players {
+		gender := Male
+		if rand.Intn(3) == 0 {
+			gender = Female
+		}
+		players[i] = Player{name: Name{string(rune('A' + i)), "Player"},
+			rating: float32(rand.Intn(1000)) / 10, gender: gender,
+			team: uint8(rand.Intn(numTeams)), baggages: []Name{}}
+	}
+	for i := range players {
+		if rand.Intn(3) == 0 {
+			players[i].baggages = append(players[i].baggages, players[rand.Intn(numPlayers)].name)
+		}
+	}
+	return players
+}
+
+func TestScoringEngineMatchesScoreSolution(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		players := makeRandomPlayers(30)
+		// Throw in a baggage for somebody who isn't playing, and a duplicate
+		players[0].baggages = append(players[0].baggages, Name{"Not", "Playing"})
+		players[1].baggages = append(players[1].baggages, players[2].name, players[2].name)
+
+		engine := newScoringEngine(newRosterIndex(players), players)
+		for move := 0; move < 50; move++ {
+			expected, _ := ScoreSolution(players)
+			assert.InDelta(t, float64(expected), float64(engine.score()), 1e-3)
+			engine.move(rand.Intn(len(players)), uint8(rand.Intn(numTeams)))
+		}
+	}
+}
+
+func loadSamplePlayers() []Player {
+	players := ParsePlayers("sample_players.csv")
+	ParseBaggages("sample_baggages.csv", players)
+	randomizeTeams(players)
+	return players
+}
+
+func BenchmarkScoreSolution(b *testing.B) {
+	players := loadSamplePlayers()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ScoreSolution(players)
+	}
+}
+
+func BenchmarkScoringEngine(b *testing.B) {
+	players := loadSamplePlayers()
+	index := newRosterIndex(players)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newScoringEngine(index, players).score()
+	}
+}
+
+func BenchmarkScoringEngineMove(b *testing.B) {
+	players := loadSamplePlayers()
+	engine := newScoringEngine(newRosterIndex(players), players)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.move(rand.Intn(len(players)), uint8(rand.Intn(numTeams)))
+		engine.score()
+	}
+}
+
+func BenchmarkBreed(b *testing.B) {
+	players := loadSamplePlayers()
+	index := newRosterIndex(players)
+	parent1 := Solution{players, 0}
+	parent2Players := make([]Player, len(players))
+	copy(parent2Players, players)
+	randomizeTeams(parent2Players)
+	parent2 := Solution{parent2Players, 0}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		breed(index, parent1, parent2)
+	}
+}
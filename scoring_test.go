@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smallRoster is a random roster of up to 12 players, so that teams are often
+// empty or have just one player of a gender
+type smallRoster []Player
+
+func (smallRoster) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(smallRoster(makeRandomPlayers(r.Intn(13))))
+}
+
+func TestScoringNeverNaN(t *testing.T) {
+	property := func(roster smallRoster) bool {
+		players := []Player(roster)
+		totalScore, rawScores := ScoreSolution(players)
+		for _, rawScore := range rawScores {
+			if math.IsNaN(float64(rawScore)) {
+				return false
+			}
+		}
+		engineScore := newScoringEngine(newRosterIndex(players), players).score()
+		return !math.IsNaN(float64(totalScore)) &&
+			math.Abs(float64(totalScore-engineScore)) < 1e-3
+	}
+	assert.Nil(t, quick.Check(property, nil))
+}
+
+func TestSampleStandardDeviation(t *testing.T) {
+	assert.Equal(t, 0.0, sampleStandardDeviation([]float64{}))
+	assert.Equal(t, 0.0, sampleStandardDeviation([]float64{3}))
+	// Equal values can't round to a negative variance
+	assert.InDelta(t, 0.0, sampleStandardDeviation([]float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1}), 1e-9)
+	assert.InDelta(t, 1.0, sampleStandardDeviation([]float64{1, 2, 3}), 1e-9)
+}
+
+func TestNaNPenalty(t *testing.T) {
+	players := makeRandomPlayers(12)
+	players[0].rating = float32(math.NaN())
+	totalScore, _ := ScoreSolution(players)
+	assert.False(t, math.IsNaN(float64(totalScore)))
+	assert.True(t, totalScore >= nanPenalty)
+}
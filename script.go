@@ -0,0 +1,127 @@
+// Criteria scripted in Starlark (a dialect of Python), for one-off balancing
+// rules. A script gives a value for each team, and like ratingDifference, the
+// criterion's score is the standard deviation of those values.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+func init() {
+	// Ratings are floats, so scripts need them
+	resolve.AllowFloat = true
+	resolve.AllowLambda = true
+	resolve.AllowSet = true
+}
+
+// scriptCriterion scores teams with a Starlark function called value, which
+// takes a list of the players on a team and returns a number
+type scriptCriterion struct {
+	name  string
+	value starlark.Value
+	// only the first error running the script is reported
+	reportError sync.Once
+}
+
+// newScriptCriterion loads a script. The argument is either the name of a
+// Starlark file (ending in .star) that defines value(team), or an expression
+// that gives the value of team.
+//
+// Returns error if the script can't be read or doesn't define value.
+func newScriptCriterion(name string, argument string) (Criterion, error) {
+	filename := name + ".star"
+	var src interface{} = "def value(team):\n    return " + argument + "\n"
+	if strings.HasSuffix(argument, ".star") {
+		filename = argument
+		contents, err := os.ReadFile(argument)
+		if err != nil {
+			return nil, err
+		}
+		src = contents
+	}
+	globals, err := starlark.ExecFile(&starlark.Thread{Name: name}, filename, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("criterion '%s': %v", name, err)
+	}
+	// The criterion is called from several goroutines at once, so the script's
+	// globals mustn't change after it's loaded
+	globals.Freeze()
+	value, found := globals["value"]
+	if _, callable := value.(starlark.Callable); !found || !callable {
+		return nil, fmt.Errorf("criterion '%s': script doesn't define value(team)", name)
+	}
+	return &scriptCriterion{name: name, value: value}, nil
+}
+
+// starlarkPlayer describes the player to a script. It has the player's name,
+// first_name, last_name, rating, gender ("Male" or "Female") and attributes (a
+// dict of every column in the players file, as strings).
+func starlarkPlayer(player Player) starlark.Value {
+	gender := "Male"
+	if player.gender == Female {
+		gender = "Female"
+	}
+	attributes := starlark.NewDict(len(player.attributes))
+	for column, value := range player.attributes {
+		attributes.SetKey(starlark.String(column), starlark.String(value))
+	}
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"name":       starlark.String(player.name.String()),
+		"first_name": starlark.String(player.name.firstName),
+		"last_name":  starlark.String(player.name.lastName),
+		"rating":     starlark.Float(player.rating),
+		"gender":     starlark.String(gender),
+		"attributes": attributes,
+	})
+}
+
+func (c *scriptCriterion) Name() string {
+	return c.name
+}
+
+func (c *scriptCriterion) Score(teams []Team) Score {
+	score, _ := c.scoreAndValues(teams)
+	return score
+}
+
+// scoreAndValues is the standard deviation of the teams' values, and the values
+func (c *scriptCriterion) scoreAndValues(teams []Team) (Score, []float64) {
+	values := c.RawValues(teams)
+	return Score(sampleStandardDeviation(values)), values
+}
+
+// RawValues runs the script on each team. If the script fails, or doesn't
+// return a number, the team's value is NaN.
+func (c *scriptCriterion) RawValues(teams []Team) []float64 {
+	thread := &starlark.Thread{Name: c.name}
+	values := make([]float64, len(teams))
+	for i, team := range teams {
+		players := make([]starlark.Value, len(team.players))
+		for j, player := range team.players {
+			players[j] = starlarkPlayer(player)
+		}
+		result, err := starlark.Call(
+			thread, c.value, starlark.Tuple{starlark.NewList(players)}, nil)
+		value, isNumber := starlark.AsFloat(result)
+		if err == nil && !isNumber {
+			err = fmt.Errorf("value(team) returned %v, which isn't a number", result)
+		}
+		if err != nil {
+			c.reportError.Do(func() {
+				newLog.Warning("Criterion '%s' failed: %v", c.name, err)
+			})
+			value = math.NaN()
+		}
+		values[i] = value
+	}
+	return values
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTallTeams() []Team {
+	teams := make([]Team, numTeams)
+	for i := range teams {
+		height := "170"
+		if i == 0 {
+			height = "200"
+		}
+		teams[i].players = []Player{{rating: 50, gender: Male, team: uint8(i),
+			attributes: map[string]string{"Height": height}}}
+	}
+	return teams
+}
+
+func TestScriptExpression(t *testing.T) {
+	scorer, err := newScriptCriterion("tall players",
+		`len([p for p in team if float(p.attributes["Height"]) > 190])`)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{1, 0, 0, 0, 0, 0}, scorer.RawValues(makeTallTeams()))
+	assert.InDelta(t, 0.408, float64(scorer.Score(makeTallTeams())), 1e-3)
+}
+
+func TestScriptFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ratings.star")
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"def value(team):\n"+
+			"    total = 0.0\n"+
+			"    for p in team:\n"+
+			"        if p.gender == 'Male':\n"+
+			"            total += p.rating\n"+
+			"    return total\n"), 0644))
+	scorer, err := newScriptCriterion("male ratings", filename)
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{50, 50, 50, 50, 50, 50}, scorer.RawValues(makeTallTeams()))
+
+	assert.Nil(t, os.WriteFile(filename, []byte("x = 1\n"), 0644))
+	_, err = newScriptCriterion("no value", filename)
+	assert.NotNil(t, err)
+	_, err = newScriptCriterion("bad syntax", "len(")
+	assert.NotNil(t, err)
+}
+
+func TestScriptErrors(t *testing.T) {
+	scorer, err := newScriptCriterion("not a number", `"tall"`)
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(scorer.RawValues(makeTallTeams())[0]))
+
+	// A failing script is penalized rather than spoiling the total score
+	c, err := newCriterion("missing column", "script",
+		`float(team[0].attributes["Weight"])`, nil, 0, 1)
+	assert.Nil(t, err)
+	_, weightedScore := c.weigh(c.rawScore(makeTallTeams()))
+	assert.Equal(t, nanPenalty, weightedScore)
+}
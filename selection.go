@@ -0,0 +1,68 @@
+// Choosing each generation's parents from the last generation's parents and
+// their children.
+
+package main
+
+import (
+	"sort"
+)
+
+// canonicalTeams describes which players are on the same team, relabeling the
+// teams in the order they first appear. Solutions that only differ by their
+// team labels have the same description.
+func canonicalTeams(players []Player) string {
+	var labels [numTeams]int
+	for team := range labels {
+		labels[team] = -1
+	}
+	description := make([]byte, len(players))
+	numLabels := 0
+	for i, player := range players {
+		if labels[player.team] == -1 {
+			labels[player.team] = numLabels
+			numLabels += 1
+		}
+		description[i] = byte(labels[player.team])
+	}
+	return string(description)
+}
+
+// selectParents chooses the next generation's parents from the best numElite
+// of the current parents, which must be sorted by score, and all of the
+// children. Solutions that are the same as a better one but for their team
+// labels are skipped, unless there aren't enough different solutions to go
+// around.
+//
+// Returns as many parents as there are now (if there are enough candidates),
+// sorted by score.
+func selectParents(parents []Solution, children []Solution, numElite int) []Solution {
+	if numElite > len(parents) {
+		numElite = len(parents)
+	}
+	// Elites go first so they win ties with their children
+	candidates := make([]Solution, 0, numElite+len(children))
+	candidates = append(candidates, parents[:numElite]...)
+	candidates = append(candidates, children...)
+	sort.Stable(ByScore(candidates))
+
+	selected := make([]Solution, 0, len(parents))
+	duplicates := []Solution{}
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if len(selected) == len(parents) {
+			break
+		}
+		description := canonicalTeams(candidate.players)
+		if seen[description] {
+			duplicates = append(duplicates, candidate)
+			continue
+		}
+		seen[description] = true
+		selected = append(selected, candidate)
+	}
+	for i := 0; len(selected) < len(parents) && i < len(duplicates); i++ {
+		selected = append(selected, duplicates[i])
+	}
+	sort.Stable(ByScore(selected))
+	return selected
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalTeams(t *testing.T) {
+	players := makeRandomPlayers(30)
+	assert.Equal(t, canonicalTeams(players), canonicalTeams(relabeledCopy(players)))
+
+	swapped := relabeledCopy(players)
+	for i := range swapped {
+		if swapped[i].team != swapped[0].team {
+			swapped[0].team, swapped[i].team = swapped[i].team, swapped[0].team
+			break
+		}
+	}
+	assert.NotEqual(t, canonicalTeams(players), canonicalTeams(swapped))
+}
+
+func TestSelectParents(t *testing.T) {
+	players := makeRandomPlayers(30)
+	other := makeRandomPlayers(30)
+	parents := []Solution{{players, 1}, {other, 5}, {other, 6}}
+
+	// The best parent survives children that are worse, and the relabeled
+	// copies of it are skipped
+	children := []Solution{
+		{relabeledCopy(players), 2}, {makeRandomPlayers(30), 3},
+		{makeRandomPlayers(30), 4}}
+	selected := selectParents(parents, children, 1)
+	assert.Equal(t, []Score{1, 3, 4}, []Score{
+		selected[0].score, selected[1].score, selected[2].score})
+
+	// Without elitism, only children are chosen. Duplicates fill in when there
+	// aren't enough different solutions.
+	children = []Solution{{players, 3}, {relabeledCopy(players), 2}}
+	selected = selectParents(parents, children, 0)
+	assert.Equal(t, []Score{2, 3}, []Score{selected[0].score, selected[1].score})
+}
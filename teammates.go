@@ -0,0 +1,162 @@
+// Avoiding reuniting teammates from past seasons. Pairs of players who shared
+// a team recently are penalized, and the further back the season, the less.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Weight of the criterion added with --previous-roster
+const teammatesWeight = 10
+
+// teammateDecay is how much less each season counts than the one after it. Set
+// with --previous-decay.
+var teammateDecay = 0.5
+
+// teammateHistory holds how much each pair of players is penalized for being
+// teammates again. Both players of a pair map to each other.
+type teammateHistory map[Name]map[Name]float64
+
+// newTeammateHistory adds up the pairs of teammates in each season's rosters,
+// given from the most recent season back. Pairs from the most recent season
+// count 1, and each season before counts decay times as much. Players with a
+// blank team weren't on one, so they aren't each other's teammates.
+func newTeammateHistory(seasons []map[Name]string, decay float64) teammateHistory {
+	history := make(teammateHistory)
+	for season, rosters := range seasons {
+		weight := math.Pow(decay, float64(season))
+		teams := make(map[string][]Name)
+		for name, team := range rosters {
+			if strings.TrimSpace(team) == "" {
+				continue
+			}
+			teams[team] = append(teams[team], name)
+		}
+		for _, names := range teams {
+			for i, a := range names {
+				for _, b := range names[i+1:] {
+					history.add(a, b, weight)
+					history.add(b, a, weight)
+				}
+			}
+		}
+	}
+	return history
+}
+
+func (history teammateHistory) add(a Name, b Name, weight float64) {
+	if history[a] == nil {
+		history[a] = make(map[Name]float64)
+	}
+	history[a][b] += weight
+}
+
+// repeats returns the penalty for the team's pairs of past teammates, and how
+// many pairs there are
+func (history teammateHistory) repeats(team Team) (float64, int) {
+	penalty := 0.0
+	numPairs := 0
+	for i, a := range team.players {
+		pastTeammates := history[a.name]
+		if pastTeammates == nil {
+			continue
+		}
+		for _, b := range team.players[i+1:] {
+			if weight, found := pastTeammates[b.name]; found {
+				penalty += weight
+				numPairs += 1
+			}
+		}
+	}
+	return penalty, numPairs
+}
+
+// teammatesCriterion scores the total penalty of every team's past teammates
+type teammatesCriterion struct {
+	name    string
+	history teammateHistory
+}
+
+// newTeammatesCriterion reads the previous rosters listed in the argument,
+// separated by ";" from the most recent season back.
+//
+// Returns error if no rosters are given.
+func newTeammatesCriterion(name string, argument string) (Criterion, error) {
+	if argument == "" {
+		return nil, fmt.Errorf("criterion '%s' needs previous rosters", name)
+	}
+	return readTeammatesCriterion(name, strings.Split(argument, ";")), nil
+}
+
+// readTeammatesCriterion reads the previous rosters, given from the most recent
+// season back
+func readTeammatesCriterion(name string, filenames []string) teammatesCriterion {
+	seasons := []map[Name]string{}
+	for _, filename := range filenames {
+		seasons = append(seasons, ParsePreviousRoster(filename))
+	}
+	return teammatesCriterion{name, newTeammateHistory(seasons, teammateDecay)}
+}
+
+// TeammatesCriterion makes the criterion for the previous rosters given with
+// --previous-roster. The filenames are passed through as they are, so they can
+// have a ";" in them.
+func TeammatesCriterion(filenames []string) criterion {
+	return scorerCriterion(readTeammatesCriterion("repeated teammates", filenames),
+		nil, 0, teammatesWeight)
+}
+
+func (c teammatesCriterion) Name() string {
+	return c.name
+}
+
+func (c teammatesCriterion) Score(teams []Team) Score {
+	score, _ := c.scoreAndValues(teams)
+	return score
+}
+
+// scoreAndValues is the total penalty, and the penalty on each team
+func (c teammatesCriterion) scoreAndValues(teams []Team) (Score, []float64) {
+	penalties := c.RawValues(teams)
+	total := 0.0
+	for _, penalty := range penalties {
+		total += penalty
+	}
+	return Score(total), penalties
+}
+
+// RawValues is the penalty for each team
+func (c teammatesCriterion) RawValues(teams []Team) []float64 {
+	penalties := make([]float64, len(teams))
+	for i, team := range teams {
+		penalties[i], _ = c.history.repeats(team)
+	}
+	return penalties
+}
+
+// boundFunction is the penalty when everybody is on one team
+func (c teammatesCriterion) boundFunction() criterionBoundFunction {
+	return func(players []Player) Score {
+		penalty, _ := c.history.repeats(Team{players})
+		return Score(penalty)
+	}
+}
+
+// PrintRepeatedTeammates shows how many pairs of past teammates are on each
+// team, for every criterion that looks at previous rosters
+func PrintRepeatedTeammates(teams []Team) {
+	for _, criterion := range criteriaToScore {
+		teammates, ok := criterion.scorer.(teammatesCriterion)
+		if !ok {
+			continue
+		}
+		numPairs := make([]int, len(teams))
+		for i, team := range criterion.filterTeams(teams) {
+			_, numPairs[i] = teammates.history.repeats(team)
+		}
+		fmt.Printf("%s: repeated pairings on each team: %v\n", criterion.name, numPairs)
+	}
+}
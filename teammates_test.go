@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeammateHistory(t *testing.T) {
+	a, b, c, d := Name{"A", "A"}, Name{"B", "B"}, Name{"C", "C"}, Name{"D", "D"}
+	history := newTeammateHistory([]map[Name]string{
+		{a: "1", b: "1", c: "2", d: "2"},
+		{a: "Red", b: "Red", c: "Red", d: "Blue"},
+		// Nobody without a team was anybody's teammate
+		{a: "", b: " ", c: "", d: "Blue"},
+	}, 0.5)
+	assert.Equal(t, 1.5, history[a][b])
+	assert.Equal(t, 1.5, history[b][a])
+	assert.Equal(t, 0.5, history[a][c])
+	assert.Equal(t, 1.0, history[c][d])
+
+	penalty, numPairs := history.repeats(
+		Team{[]Player{{name: a}, {name: b}, {name: c}, {name: Name{"E", "E"}}}})
+	assert.Equal(t, 2.5, penalty)
+	assert.Equal(t, 3, numPairs)
+}
+
+func TestTeammatesCriterion(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "last_season.csv")
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"First Name,Last Name,Team\nA,A,1\nB,B,1\nC,C,2\n"), 0644))
+	scorer, err := newTeammatesCriterion("repeated teammates", filename)
+	assert.Nil(t, err)
+	_, err = newTeammatesCriterion("repeated teammates", "")
+	assert.NotNil(t, err)
+
+	teams := make([]Team, numTeams)
+	teams[0].players = []Player{{name: Name{"A", "A"}}, {name: Name{"C", "C"}}}
+	teams[1].players = []Player{{name: Name{"B", "B"}}}
+	assert.Equal(t, Score(0), scorer.Score(teams))
+	teams[0].players = append(teams[0].players, teams[1].players...)
+	teams[1].players = nil
+	assert.Equal(t, Score(1), scorer.Score(teams))
+	assert.Equal(t, []float64{1, 0, 0, 0, 0, 0}, scorer.RawValues(teams))
+	assert.Equal(t, Score(1),
+		scorer.(teammatesCriterion).boundFunction()(teams[0].players))
+
+	// Filenames from the command line aren't split on ";"
+	filename = filepath.Join(t.TempDir(), "last;season.csv")
+	assert.Nil(t, os.WriteFile(filename, []byte(
+		"First Name,Last Name,Team\nA,A,1\nB,B,1\n"), 0644))
+	c := TeammatesCriterion([]string{filename})
+	assert.Equal(t, "repeated teammates", c.name)
+	assert.Equal(t, Score(1), c.rawScore(teams))
+}